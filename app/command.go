@@ -4,8 +4,11 @@ import (
 	_ "embed"
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
 	"os/exec"
+	"regexp"
+	"strconv"
 	"strings"
 	"text/template"
 
@@ -15,12 +18,86 @@ import (
 )
 
 type Command struct {
-	Exec        string  `json:"exec,omitempty" yaml:"exec,omitempty"`
-	Interactive bool    `json:"interactive,omitempty" yaml:"interactive,omitempty"`
-	Description string  `json:"description,omitempty" yaml:"description,omitempty"`
-	Params      []Param `json:"params,omitempty" yaml:"params,omitempty"`
-	OnSuccess   string  `json:"onSuccess,omitempty" yaml:"onSuccess,omitempty"`
+	Exec        CommandExec `json:"exec,omitempty" yaml:"exec,omitempty"`
+	Interactive bool        `json:"interactive,omitempty" yaml:"interactive,omitempty"`
+	Description string      `json:"description,omitempty" yaml:"description,omitempty"`
+	Params      []Param     `json:"params,omitempty" yaml:"params,omitempty"`
+	OnSuccess   string      `json:"onSuccess,omitempty" yaml:"onSuccess,omitempty"`
+	Timeout     string      `json:"timeout,omitempty" yaml:"timeout,omitempty"`
 	Env         []string
+	Preferences []Preference `json:"preferences,omitempty" yaml:"preferences,omitempty"`
+
+	// Exit is set to false to keep sunbeam open once the command has run
+	Exit *bool `json:"exit,omitempty" yaml:"exit,omitempty"`
+}
+
+// ShouldExit reports whether sunbeam exits once the command has run, which is the default.
+func (c Command) ShouldExit() bool {
+	return c.Exit == nil || *c.Exit
+}
+
+// CommandExec is either a shell script, run with sh -c, or an array of arguments passed directly to the executable.
+type CommandExec struct {
+	Script string
+	Argv   []string
+}
+
+func (e *CommandExec) UnmarshalJSON(b []byte) error {
+	var script string
+	if err := json.Unmarshal(b, &script); err == nil {
+		e.Script = script
+		return nil
+	}
+
+	var argv []string
+	if err := json.Unmarshal(b, &argv); err == nil {
+		e.Argv = argv
+		return nil
+	}
+
+	return fmt.Errorf("invalid exec: %s", b)
+}
+
+func (e *CommandExec) UnmarshalYAML(node *yaml.Node) error {
+	var script string
+	if err := node.Decode(&script); err == nil {
+		e.Script = script
+		return nil
+	}
+
+	var argv []string
+	if err := node.Decode(&argv); err == nil {
+		e.Argv = argv
+		return nil
+	}
+
+	return fmt.Errorf("invalid exec: %s", node.Value)
+}
+
+func (e CommandExec) MarshalJSON() ([]byte, error) {
+	if e.Argv != nil {
+		return json.Marshal(e.Argv)
+	}
+	return json.Marshal(e.Script)
+}
+
+func (e CommandExec) MarshalYAML() (interface{}, error) {
+	if e.Argv != nil {
+		return e.Argv, nil
+	}
+	return e.Script, nil
+}
+
+func (e CommandExec) IsZero() bool {
+	return e.Script == "" && len(e.Argv) == 0
+}
+
+// Templates returns the templates to render, the script or each of the arguments.
+func (e CommandExec) Templates() []string {
+	if e.Argv != nil {
+		return e.Argv
+	}
+	return []string{e.Script}
 }
 
 type CommandInput struct {
@@ -37,7 +114,13 @@ func (i *CommandInput) UnmarshalJSON(b []byte) error {
 
 	var boolean bool
 	if err := json.Unmarshal(b, &boolean); err == nil {
-		i.Value = b
+		i.Value = boolean
+		return nil
+	}
+
+	var number float64
+	if err := json.Unmarshal(b, &number); err == nil {
+		i.Value = number
 		return nil
 	}
 
@@ -51,18 +134,28 @@ func (i *CommandInput) UnmarshalJSON(b []byte) error {
 }
 
 func (i *CommandInput) UnmarshalYAML(node *yaml.Node) error {
+	// Any scalar can be decoded as a string, so the tag is checked first
+	switch node.ShortTag() {
+	case "!!bool":
+		var boolean bool
+		if err := node.Decode(&boolean); err == nil {
+			i.Value = boolean
+			return nil
+		}
+	case "!!int", "!!float":
+		var number float64
+		if err := node.Decode(&number); err == nil {
+			i.Value = number
+			return nil
+		}
+	}
+
 	var s string
 	if err := node.Decode(&s); err == nil {
 		i.Value = s
 		return nil
 	}
 
-	var boolean bool
-	if err := node.Decode(&boolean); err == nil {
-		i.Value = boolean
-		return nil
-	}
-
 	var input FormItem
 	if err := node.Decode(&input); err == nil {
 		i.FormItem = input
@@ -80,8 +173,8 @@ func (i CommandInput) MarshalYAML() (interface{}, error) {
 }
 
 type Preference struct {
-	Env   string   `json:"env"`
-	Input FormItem `json:"input"`
+	Env   string   `json:"env" yaml:"env"`
+	Input FormItem `json:"input" yaml:"input"`
 }
 
 type Param struct {
@@ -104,52 +197,200 @@ type FormItem struct {
 }
 
 type CommandParams struct {
-	Input string
-	Env   []string
-	With  map[string]any
+	Input       string
+	Env         []string
+	With        map[string]any
+	Preferences map[string]any `json:",omitempty"`
+	// Query is the search query of generator lists
+	Query string `json:",omitempty"`
+}
+
+type ValidationError struct {
+	Param   string `json:"param"`
+	Message string `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("invalid param %s: %s", e.Param, e.Message)
 }
 
-func (c Command) CheckMissingParams(with map[string]any) error {
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Convert converts a value to the type of the param.
+// Strings are parsed for boolean, number and integer params, since form inputs and query strings only provide strings.
+func (p Param) Convert(value any) (any, error) {
+	switch p.Type {
+	case "boolean":
+		switch value := value.(type) {
+		case bool:
+			return value, nil
+		case string:
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("expected a boolean, got %q", value)
+			}
+			return b, nil
+		}
+		return nil, fmt.Errorf("expected a boolean, got %v", value)
+	case "number":
+		switch value := value.(type) {
+		case float64:
+			return value, nil
+		case int:
+			return float64(value), nil
+		case string:
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("expected a number, got %q", value)
+			}
+			return f, nil
+		}
+		return nil, fmt.Errorf("expected a number, got %v", value)
+	case "integer":
+		switch value := value.(type) {
+		case int:
+			return value, nil
+		case float64:
+			if value != math.Trunc(value) {
+				return nil, fmt.Errorf("expected an integer, got %v", value)
+			}
+			return int(value), nil
+		case string:
+			i, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("expected an integer, got %q", value)
+			}
+			return i, nil
+		}
+		return nil, fmt.Errorf("expected an integer, got %v", value)
+	default:
+		if value, ok := value.(string); ok {
+			return value, nil
+		}
+		return nil, fmt.Errorf("expected a string, got %v", value)
+	}
+}
+
+// ValidateParams checks that every param of the command has a value matching its type, enum and pattern.
+// It returns the params converted to their declared type, with the defaults of the missing params.
+// Values which are not declared as params are passed through.
+func (c Command) ValidateParams(with map[string]any) (map[string]any, error) {
+	params := make(map[string]any)
+	for name, value := range with {
+		params[name] = value
+	}
+
+	var errs ValidationErrors
 	for _, param := range c.Params {
-		if _, ok := with[param.Name]; !ok && param.Default == nil {
-			return fmt.Errorf("missing param: %s", param.Name)
+		value, ok := with[param.Name]
+		if !ok || value == nil {
+			if param.Default == nil {
+				errs = append(errs, ValidationError{Param: param.Name, Message: "missing value"})
+				continue
+			}
+			value = param.Default
+		}
+
+		value, err := param.Convert(value)
+		if err != nil {
+			errs = append(errs, ValidationError{Param: param.Name, Message: err.Error()})
+			continue
+		}
+
+		if len(param.Enum) > 0 {
+			valid := false
+			for _, choice := range param.Enum {
+				if fmt.Sprint(value) == choice {
+					valid = true
+					break
+				}
+			}
+
+			if !valid {
+				errs = append(errs, ValidationError{Param: param.Name, Message: fmt.Sprintf("expected one of %s, got %v", strings.Join(param.Enum, ", "), value)})
+				continue
+			}
 		}
+
+		if param.Pattern != "" {
+			re, err := regexp.Compile(param.Pattern)
+			if err != nil {
+				errs = append(errs, ValidationError{Param: param.Name, Message: fmt.Sprintf("invalid pattern %s: %s", param.Pattern, err)})
+				continue
+			}
+
+			if !re.MatchString(fmt.Sprint(value)) {
+				errs = append(errs, ValidationError{Param: param.Name, Message: fmt.Sprintf("%v does not match pattern %s", value, param.Pattern)})
+				continue
+			}
+		}
+
+		params[param.Name] = value
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
 	}
 
-	return nil
+	return params, nil
 }
 
 func (c Command) Cmd(params CommandParams, dir string) (*exec.Cmd, error) {
-	var err error
-
-	err = c.CheckMissingParams(params.With)
+	with, err := c.ValidateParams(params.With)
 	if err != nil {
 		return nil, err
 	}
 
-	funcMap := template.FuncMap{}
+	// The query is available as a template variable, unless a param shadows it
+	if _, ok := with["query"]; !ok {
+		with["query"] = params.Query
+	}
 
-	for sanitizedKey, value := range params.With {
-		value := value
-		sanitizedKey = strings.Replace(sanitizedKey, "-", "_", -1)
-		funcMap[sanitizedKey] = func() any {
-			if value, ok := value.(string); ok {
-				return shellescape.Quote(value)
+	var cmd *exec.Cmd
+	if c.Exec.Argv != nil {
+		if len(c.Exec.Argv) == 0 {
+			return nil, fmt.Errorf("exec must contain at least one argument")
+		}
+
+		// Arguments are passed as is to the executable, so values must not be quoted
+		funcMap := TemplateFuncMap(with, Stringify)
+		argv := make([]string, len(c.Exec.Argv))
+		for i, arg := range c.Exec.Argv {
+			argv[i], err = utils.RenderString(arg, funcMap)
+			if err != nil {
+				return nil, err
 			}
+		}
+
+		cmd = exec.Command(argv[0], argv[1:]...)
+	} else {
+		funcMap := TemplateFuncMap(with, func(value any) string {
+			return shellescape.Quote(Stringify(value))
+		})
 
-			return value
+		rendered, err := utils.RenderString(c.Exec.Script, funcMap)
+		if err != nil {
+			return nil, err
 		}
-	}
 
-	rendered, err := utils.RenderString(c.Exec, funcMap)
-	if err != nil {
-		return nil, err
+		cmd = exec.Command("sh", "-c", rendered)
 	}
 
-	cmd := exec.Command("sh", "-c", rendered)
 	cmd.Dir = dir
 	cmd.Env = append(cmd.Env, os.Environ()...)
 	cmd.Env = append(cmd.Env, params.Env...)
+	cmd.Env = append(cmd.Env, fmt.Sprintf("SUNBEAM_QUERY=%s", params.Query))
+	for env, value := range params.Preferences {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%v", env, value))
+	}
 
 	if params.Input != "" {
 		cmd.Stdin = strings.NewReader(params.Input)
@@ -158,27 +399,120 @@ func (c Command) Cmd(params CommandParams, dir string) (*exec.Cmd, error) {
 	return cmd, nil
 }
 
+// TemplateFuncMap exposes each param as a template function returning its formatted value.
+// Dashes in param names are replaced by underscores.
+func TemplateFuncMap(with map[string]any, format func(any) string) template.FuncMap {
+	funcMap := template.FuncMap{}
+	for name, value := range with {
+		value := value
+		funcMap[strings.Replace(name, "-", "_", -1)] = func() string {
+			return format(value)
+		}
+	}
+
+	return funcMap
+}
+
+// Stringify formats param values predictably: booleans as true or false, and numbers without exponent.
+func Stringify(value any) string {
+	switch value := value.(type) {
+	case string:
+		return value
+	case bool:
+		return strconv.FormatBool(value)
+	case int:
+		return strconv.Itoa(value)
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
 type Page struct {
 	Type  string `json:"type"`
 	Title string `json:"title"`
 
 	Detail
 	List
+	Form
 }
 
 type Detail struct {
-	Preview Preview  `json:"preview"`
-	Actions []Action `json:"actions"`
+	Preview  Preview        `json:"preview"`
+	Actions  []Action       `json:"actions"`
+	Metadata []MetadataItem `json:"metadata,omitempty"`
+}
+
+// MetadataItem is a label/value pair displayed next to the text of a detail page.
+type MetadataItem struct {
+	Label string        `json:"label"`
+	Value string        `json:"value,omitempty"`
+	Url   string        `json:"url,omitempty"`
+	Tags  []MetadataTag `json:"tags,omitempty"`
+}
+
+type MetadataTag struct {
+	Text  string `json:"text"`
+	Color string `json:"color,omitempty"`
 }
 
 type List struct {
-	EmptyText   string     `json:"emptyText"`
-	ShowPreview bool       `json:"showPreview,omitempty" yaml:"showPreview"`
-	Items       []ListItem `json:"items"`
+	EmptyText   string `json:"emptyText"`
+	ShowPreview bool   `json:"showPreview,omitempty" yaml:"showPreview"`
+	Streaming   bool   `json:"streaming,omitempty" yaml:"streaming,omitempty"`
+	Generator   bool   `json:"generator,omitempty" yaml:"generator,omitempty"`
+	MultiSelect bool   `json:"multiSelect,omitempty" yaml:"multiSelect,omitempty"`
+	// MatchMode is one of fuzzy, exact, prefix or extended
+	MatchMode string     `json:"matchMode,omitempty" yaml:"matchMode,omitempty"`
+	Items     []ListItem `json:"items"`
+	Sections  []Section  `json:"sections,omitempty" yaml:"sections,omitempty"`
+	// PreviewLayout overrides the preview layout of the user config
+	PreviewLayout PreviewLayout `json:"previewLayout,omitempty" yaml:"previewLayout,omitempty"`
+}
+
+// PreviewLayout describes how the preview of a list is displayed, zero values are left to the defaults.
+type PreviewLayout struct {
+	// Position is either right or bottom
+	Position string `json:"position,omitempty" yaml:"position,omitempty"`
+	// Ratio is the share of the width, or the height, taken by the preview
+	Ratio float64 `json:"ratio,omitempty" yaml:"ratio,omitempty"`
+	// MinWidth is the terminal width below which a preview on the right is hidden
+	MinWidth int `json:"minWidth,omitempty" yaml:"minWidth,omitempty"`
+}
+
+// Section groups the items of a list under a title.
+type Section struct {
+	Title string     `json:"title"`
+	Items []ListItem `json:"items"`
+}
+
+type Form struct {
+	Fields []FormField `json:"fields"`
+	Submit FormSubmit  `json:"submit"`
+}
+
+type FormField struct {
+	Name string `json:"name"`
+	FormItem
+}
+
+// FormSubmit runs a command of the extension with the values of the form fields, in addition to the static params.
+type FormSubmit struct {
+	Command   string                  `json:"command"`
+	With      map[string]CommandInput `json:"with"`
+	OnSuccess string                  `json:"onSuccess"`
 }
 
 type Preview struct {
 	Text string `json:"text"`
+	// Format is one of text, markdown, ansi or code, in which case the language is used for syntax highlighting
+	Format   string `json:"format,omitempty"`
+	Language string `json:"language,omitempty"`
+	// Ttl is the duration for which the output of the preview command is cached, it is cached until sunbeam exits by default
+	Ttl string `json:"ttl,omitempty"`
 	PreviewCommand
 }
 
@@ -194,9 +528,9 @@ func (p *Preview) UnmarshalJSON(b []byte) error {
 		return nil
 	}
 
-	var cmd PreviewCommand
-	if err := json.Unmarshal(b, &cmd); err == nil {
-		p.PreviewCommand = cmd
+	// The alias type does not implement UnmarshalJSON, avoiding an infinite recursion
+	type preview Preview
+	if err := json.Unmarshal(b, (*preview)(p)); err == nil {
 		return nil
 	}
 
@@ -210,6 +544,8 @@ type ListItem struct {
 	Preview     Preview  `json:"preview"`
 	Accessories []string `json:"accessories"`
 	Actions     []Action `json:"actions"`
+	// Payload is passed to batch actions instead of the id of the item
+	Payload any `json:"payload,omitempty"`
 }
 
 type Action struct {
@@ -221,8 +557,57 @@ type Action struct {
 
 	Url  string
 	Path string
+	// Line is the line at which the editor opens the file of edit actions
+	Line int
 
 	Command   string
 	With      map[string]CommandInput
 	OnSuccess string
+	// Batch commands receive the selected items of multi-select lists on stdin
+	Batch bool
+
+	// Http actions send a request to the url, the body is rendered with the values of the inputs
+	Method  string
+	Headers map[string]string
+	Body    string
+	Timeout string
+	Tls     HttpTls
+
+	// Confirm asks the user for confirmation before running the action
+	Confirm ActionConfirm
+	// Exit is set to false to keep sunbeam open once the action has run
+	Exit *bool
+}
+
+// ShouldExit reports whether sunbeam exits once the action has run, which is the default.
+func (a Action) ShouldExit() bool {
+	return a.Exit == nil || *a.Exit
+}
+
+// ActionConfirm is either a boolean, or an object overriding the title and the message of the confirmation.
+type ActionConfirm struct {
+	Enabled bool
+	Title   string
+	Message string
+}
+
+func (c *ActionConfirm) UnmarshalJSON(b []byte) error {
+	var enabled bool
+	if err := json.Unmarshal(b, &enabled); err == nil {
+		c.Enabled = enabled
+		return nil
+	}
+
+	var confirm struct {
+		Title   string
+		Message string
+	}
+	if err := json.Unmarshal(b, &confirm); err == nil {
+		c.Enabled = true
+		c.Title = confirm.Title
+		c.Message = confirm.Message
+		return nil
+	}
+
+	return fmt.Errorf("invalid confirm: %s", b)
 }
@@ -0,0 +1,173 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestValidateParams(t *testing.T) {
+	command := Command{
+		Params: []Param{
+			{Name: "count", Type: "integer", Default: 10},
+			{Name: "ratio", Type: "number"},
+			{Name: "verbose", Type: "boolean", Default: false},
+			{Name: "format", Type: "string", Enum: []string{"json", "yaml"}},
+			{Name: "issue", Type: "string", Pattern: `^[A-Z]+-\d+$`},
+		},
+	}
+
+	t.Run("valid params", func(t *testing.T) {
+		params, err := command.ValidateParams(map[string]any{
+			"ratio":  "0.5",
+			"format": "json",
+			"issue":  "SUN-42",
+			"extra":  "passed through",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := map[string]any{
+			"count":   10,
+			"ratio":   0.5,
+			"verbose": false,
+			"format":  "json",
+			"issue":   "SUN-42",
+			"extra":   "passed through",
+		}
+		if !reflect.DeepEqual(params, want) {
+			t.Errorf("got %v, want %v", params, want)
+		}
+	})
+
+	t.Run("invalid params", func(t *testing.T) {
+		_, err := command.ValidateParams(map[string]any{
+			"count":  1.5,
+			"ratio":  "half",
+			"format": "toml",
+			"issue":  "sun-42",
+		})
+
+		var validationErrors ValidationErrors
+		if !errors.As(err, &validationErrors) {
+			t.Fatalf("expected validation errors, got %v", err)
+		}
+
+		invalidParams := make([]string, len(validationErrors))
+		for i, validationError := range validationErrors {
+			invalidParams[i] = validationError.Param
+		}
+
+		want := []string{"count", "ratio", "format", "issue"}
+		if !reflect.DeepEqual(invalidParams, want) {
+			t.Errorf("got errors for %v, want %v", invalidParams, want)
+		}
+	})
+
+	t.Run("missing params", func(t *testing.T) {
+		_, err := command.ValidateParams(map[string]any{})
+
+		var validationErrors ValidationErrors
+		if !errors.As(err, &validationErrors) || len(validationErrors) != 3 {
+			t.Fatalf("expected 3 validation errors, got %v", err)
+		}
+	})
+}
+
+func TestActionParams(t *testing.T) {
+	command := Command{
+		Params: []Param{
+			{Name: "force", Type: "boolean"},
+			{Name: "count", Type: "integer"},
+			{Name: "ratio", Type: "number"},
+			{Name: "label", Type: "string"},
+		},
+	}
+
+	// Actions of pages are written in json, root items of manifests in yaml
+	cases := map[string]func() (map[string]CommandInput, error){
+		"json": func() (map[string]CommandInput, error) {
+			var action Action
+			err := json.Unmarshal([]byte(`{
+				"type": "run-command",
+				"command": "upload",
+				"with": {"force": true, "count": 3, "ratio": 0.5, "label": "3", "name": {"type": "textfield", "default": 3}}
+			}`), &action)
+			return action.With, err
+		},
+		"yaml": func() (map[string]CommandInput, error) {
+			var rootItem RootItem
+			err := yaml.Unmarshal([]byte(`
+command: upload
+with:
+  force: true
+  count: 3
+  ratio: 0.5
+  label: "3"
+  name:
+    type: textfield
+    default: 3
+`), &rootItem)
+			return rootItem.With, err
+		},
+	}
+
+	for key, decode := range cases {
+		decode := decode
+		t.Run(key, func(t *testing.T) {
+			inputs, err := decode()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if inputs["force"].Value != true || inputs["count"].Value != float64(3) || inputs["label"].Value != "3" {
+				t.Errorf("values should keep their type, got %#v, %#v and %#v", inputs["force"].Value, inputs["count"].Value, inputs["label"].Value)
+			}
+
+			if inputs["name"].Value != nil {
+				t.Errorf("form inputs should not have a value, got %v", inputs["name"].Value)
+			}
+
+			with := make(map[string]any)
+			for name, input := range inputs {
+				if input.Value != nil {
+					with[name] = input.Value
+				}
+			}
+
+			params, err := command.ValidateParams(with)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := map[string]any{"force": true, "count": 3, "ratio": 0.5, "label": "3"}
+			if !reflect.DeepEqual(params, want) {
+				t.Errorf("got %v, want %v", params, want)
+			}
+		})
+	}
+}
+
+func TestActionConfirm(t *testing.T) {
+	cases := map[string]ActionConfirm{
+		`{"type": "copy-text"}`:                                    {},
+		`{"type": "copy-text", "confirm": false}`:                  {},
+		`{"type": "copy-text", "confirm": true}`:                   {Enabled: true},
+		`{"type": "copy-text", "confirm": {"title": "Copy?"}}`:     {Enabled: true, Title: "Copy?"},
+		`{"type": "copy-text", "confirm": {"message": "Really?"}}`: {Enabled: true, Message: "Really?"},
+	}
+
+	for input, want := range cases {
+		var action Action
+		if err := json.Unmarshal([]byte(input), &action); err != nil {
+			t.Fatal(err)
+		}
+		if action.Confirm != want {
+			t.Errorf("%s: got %+v, want %+v", input, action.Confirm, want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// WithTimeout returns a context that is done when the parent context is, or when the command timeout expires.
+func (c Command) WithTimeout(parent context.Context) (context.Context, context.CancelFunc) {
+	if c.Timeout == "" {
+		return context.WithCancel(parent)
+	}
+
+	timeout, err := time.ParseDuration(c.Timeout)
+	if err != nil {
+		return context.WithCancel(parent)
+	}
+
+	return context.WithTimeout(parent, timeout)
+}
+
+// Stream starts the command in its own process group and returns a reader over its standard output.
+// The output must be read until EOF before calling wait, which returns the exit error of the command.
+// When the context is done, the whole process group is killed and wait returns the context error.
+func Stream(ctx context.Context, cmd *exec.Cmd) (stdout io.Reader, wait func() error, err error) {
+	stdout, err = cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var stderr bytes.Buffer
+	if cmd.Stderr == nil {
+		cmd.Stderr = &stderr
+	}
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	if err := cmd.Start(); err != nil {
+		return nil, nil, err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			// A negative pid targets the process group, so that the children of the command are killed too
+			syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		case <-done:
+		}
+	}()
+
+	wait = func() error {
+		err := cmd.Wait()
+		close(done)
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			exitErr.Stderr = stderr.Bytes()
+		}
+		return err
+	}
+
+	return stdout, wait, nil
+}
+
+// Output runs the command in its own process group and returns its standard output.
+// When the context is done, the whole process group is killed and the context error is returned.
+func Output(ctx context.Context, cmd *exec.Cmd) ([]byte, error) {
+	stdout, wait, err := Stream(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := io.ReadAll(stdout)
+	if err := wait(); err != nil {
+		return output, err
+	}
+
+	return output, err
+}
@@ -0,0 +1,43 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestOutputTimeout(t *testing.T) {
+	command := Command{Timeout: "100ms"}
+	ctx, cancel := command.WithTimeout(context.Background())
+	defer cancel()
+
+	// The grandchild keeps stdout open, so Output only returns if the whole process group is killed
+	cmd := exec.Command("sh", "-c", "sleep 10 & wait")
+	start := time.Now()
+	_, err := Output(ctx, cmd)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a deadline exceeded error, got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("command was not killed after the timeout, took %s", elapsed)
+	}
+}
+
+func TestOutputExitError(t *testing.T) {
+	cmd := exec.Command("sh", "-c", "echo out; echo err >&2; exit 3")
+	output, err := Output(context.Background(), cmd)
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("expected an exit error, got %v", err)
+	}
+	if exitErr.ExitCode() != 3 || string(exitErr.Stderr) != "err\n" {
+		t.Errorf("got exit code %d and stderr %q", exitErr.ExitCode(), exitErr.Stderr)
+	}
+	if string(output) != "out\n" {
+		t.Errorf("got output %q, want %q", output, "out\n")
+	}
+}
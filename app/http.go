@@ -0,0 +1,161 @@
+package app
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pomdtr/sunbeam/utils"
+)
+
+// HttpTls configures the connection of http actions to servers using https.
+type HttpTls struct {
+	// Insecure skips the verification of the server certificate
+	Insecure bool
+	// CaCert is a pem file containing the certificate authorities trusted in addition to the system ones
+	CaCert string
+	// Cert and Key are pem files used to authenticate the client
+	Cert string
+	Key  string
+}
+
+// Request builds the request of an http action.
+// The values of the inputs are json encoded in the body if the content type is json, and inserted as is otherwise.
+func (a Action) Request(ctx context.Context, with map[string]any) (*http.Request, error) {
+	method := a.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	format := Stringify
+	for name, value := range a.Headers {
+		if strings.EqualFold(name, "Content-Type") && strings.Contains(value, "json") {
+			format = func(value any) string {
+				encoded, _ := json.Marshal(value)
+				return string(encoded)
+			}
+		}
+	}
+
+	body, err := utils.RenderString(a.Body, TemplateFuncMap(with, format))
+	if err != nil {
+		return nil, err
+	}
+
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, strings.ToUpper(method), a.Url, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, value := range a.Headers {
+		req.Header.Set(name, value)
+	}
+
+	return req, nil
+}
+
+// Client returns the http client of the action, configured with its tls options.
+func (a Action) Client() (*http.Client, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: a.Tls.Insecure,
+	}
+
+	if a.Tls.CaCert != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+
+		caCertPath, err := utils.ResolvePath(a.Tls.CaCert)
+		if err != nil {
+			return nil, err
+		}
+		caCert, err := os.ReadFile(caCertPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ca certificate: %s", err)
+		}
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no certificate found in %s", a.Tls.CaCert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if a.Tls.Cert != "" || a.Tls.Key != "" {
+		certPath, err := utils.ResolvePath(a.Tls.Cert)
+		if err != nil {
+			return nil, err
+		}
+		keyPath, err := utils.ResolvePath(a.Tls.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %s", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &http.Client{Transport: transport}, nil
+}
+
+// WithTimeout returns a context that is done when the parent context is, or when the timeout of the action expires.
+func (a Action) WithTimeout(parent context.Context) (context.Context, context.CancelFunc) {
+	return Command{Timeout: a.Timeout}.WithTimeout(parent)
+}
+
+// Send sends the request of an http action, and returns the body of the response.
+// Responses with a status code outside of the 2xx range are errors.
+func (a Action) Send(ctx context.Context, with map[string]any) ([]byte, error) {
+	if a.Timeout != "" {
+		if _, err := time.ParseDuration(a.Timeout); err != nil {
+			return nil, fmt.Errorf("invalid timeout: %s", a.Timeout)
+		}
+	}
+
+	ctx, cancel := a.WithTimeout(ctx)
+	defer cancel()
+
+	req, err := a.Request(ctx, with)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := a.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, fmt.Errorf("request failed with status code %d:\n%s", res.StatusCode, body)
+	}
+
+	return body, nil
+}
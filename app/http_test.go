@@ -0,0 +1,165 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestActionSend(t *testing.T) {
+	t.Run("request", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			json.NewEncoder(w).Encode(map[string]string{
+				"method":        r.Method,
+				"path":          r.URL.Path,
+				"authorization": r.Header.Get("Authorization"),
+				"body":          string(body),
+			})
+		}))
+		defer server.Close()
+
+		action := Action{
+			Type:   "http",
+			Method: "post",
+			Url:    server.URL + "/issues/SUN-42/comments",
+			Headers: map[string]string{
+				"Authorization": "Bearer token",
+				"Content-Type":  "application/json",
+			},
+			// Values are json encoded, since the content type is json
+			Body: `{"body": ${{ comment }}, "notify": ${{ notify }}}`,
+		}
+
+		output, err := action.Send(context.Background(), map[string]any{
+			"comment": `Fixed in "main"`,
+			"notify":  true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got map[string]string
+		if err := json.Unmarshal(output, &got); err != nil {
+			t.Fatal(err)
+		}
+
+		want := map[string]string{
+			"method":        "POST",
+			"path":          "/issues/SUN-42/comments",
+			"authorization": "Bearer token",
+			"body":          `{"body": "Fixed in \"main\"", "notify": true}`,
+		}
+		for key, value := range want {
+			if got[key] != value {
+				t.Errorf("%s: got %q, want %q", key, got[key], value)
+			}
+		}
+	})
+
+	t.Run("text body", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.Copy(w, r.Body)
+		}))
+		defer server.Close()
+
+		action := Action{Type: "http", Method: "PUT", Url: server.URL, Body: "status=${{ status }}"}
+		output, err := action.Send(context.Background(), map[string]any{"status": "done"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(output) != "status=done" {
+			t.Errorf("got %q, want %q", output, "status=done")
+		}
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "issue not found", http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		_, err := Action{Type: "http", Url: server.URL}.Send(context.Background(), nil)
+		if err == nil || !strings.Contains(err.Error(), "404") || !strings.Contains(err.Error(), "issue not found") {
+			t.Errorf("got %v, want the status code and the body of the response", err)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		done := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-done:
+			case <-r.Context().Done():
+			}
+		}))
+		defer server.Close()
+		defer close(done)
+
+		start := time.Now()
+		_, err := Action{Type: "http", Url: server.URL, Timeout: "100ms"}.Send(context.Background(), nil)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("got %v, want a deadline exceeded error", err)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("request was not cancelled after the timeout, took %s", elapsed)
+		}
+	})
+
+	t.Run("tls", func(t *testing.T) {
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("ok"))
+		}))
+		// The rejected handshake is logged by the server
+		server.Config.ErrorLog = log.New(io.Discard, "", 0)
+		server.StartTLS()
+		defer server.Close()
+
+		if _, err := (Action{Type: "http", Url: server.URL}).Send(context.Background(), nil); err == nil {
+			t.Errorf("the self-signed certificate of the server should be rejected")
+		}
+
+		if _, err := (Action{Type: "http", Url: server.URL, Tls: HttpTls{Insecure: true}}).Send(context.Background(), nil); err != nil {
+			t.Errorf("insecure request failed: %s", err)
+		}
+
+		caCert := filepath.Join(t.TempDir(), "ca.pem")
+		pemBlock := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+		if err := os.WriteFile(caCert, pemBlock, 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := (Action{Type: "http", Url: server.URL, Tls: HttpTls{CaCert: caCert}}).Send(context.Background(), nil); err != nil {
+			t.Errorf("request trusting the server certificate failed: %s", err)
+		}
+	})
+}
+
+func TestHttpActionMethod(t *testing.T) {
+	validate := func(method string) error {
+		var page any
+		json.Unmarshal([]byte(`{"type": "list", "items": [{"title": "SUN-42", "actions": [{"type": "http", "title": "Comment", "url": "https://jira.example.com", "method": "`+method+`"}]}]}`), &page)
+		return PageSchema.Validate(page)
+	}
+
+	// Methods are uppercased when the request is sent
+	for _, method := range []string{"POST", "post", "Delete"} {
+		if err := validate(method); err != nil {
+			t.Errorf("%s should be valid: %s", method, err)
+		}
+	}
+
+	if err := validate("FETCH"); err == nil {
+		t.Errorf("FETCH should be rejected")
+	}
+}
@@ -0,0 +1,94 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	"github.com/pomdtr/sunbeam/utils"
+)
+
+type LockFile struct {
+	path       string
+	Extensions map[string]LockedExtension `json:"extensions"`
+}
+
+type LockedExtension struct {
+	Name        string    `json:"name"`
+	Source      string    `json:"source"`
+	Path        string    `json:"path,omitempty"`
+	Ref         string    `json:"ref,omitempty"`
+	Commit      string    `json:"commit,omitempty"`
+	Sha256      string    `json:"sha256,omitempty"`
+	Version     string    `json:"version"`
+	InstalledAt time.Time `json:"installedAt"`
+}
+
+// IsLocal reports whether the extension was installed by symlinking a local directory.
+func (e LockedExtension) IsLocal() bool {
+	return path.IsAbs(e.Source) && !e.IsArchive()
+}
+
+// IsArchive reports whether the extension was installed from a tarball or a zip archive.
+func (e LockedExtension) IsArchive() bool {
+	return utils.IsArchive(e.Source)
+}
+
+func (api *Api) LockFilePath() string {
+	return path.Join(path.Dir(api.ExtensionRoot), "extensions.lock")
+}
+
+func LoadLockFile(lockPath string) (*LockFile, error) {
+	lockFile := LockFile{
+		path:       lockPath,
+		Extensions: make(map[string]LockedExtension),
+	}
+
+	if _, err := os.Stat(lockPath); os.IsNotExist(err) {
+		return &lockFile, nil
+	}
+
+	lockBytes, err := os.ReadFile(lockPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lock file: %w", err)
+	}
+
+	if err := json.Unmarshal(lockBytes, &lockFile); err != nil {
+		return nil, fmt.Errorf("failed to parse lock file: %w", err)
+	}
+
+	if lockFile.Extensions == nil {
+		lockFile.Extensions = make(map[string]LockedExtension)
+	}
+
+	return &lockFile, nil
+}
+
+func (l *LockFile) Save() error {
+	if err := os.MkdirAll(path.Dir(l.path), 0755); err != nil {
+		return fmt.Errorf("failed to create lock file directory: %w", err)
+	}
+
+	lockBytes, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal lock file: %w", err)
+	}
+
+	if err := os.WriteFile(l.path, lockBytes, 0644); err != nil {
+		return fmt.Errorf("failed to write lock file: %w", err)
+	}
+
+	return nil
+}
+
+func (l *LockFile) Lock(extension LockedExtension) error {
+	l.Extensions[extension.Name] = extension
+	return l.Save()
+}
+
+func (l *LockFile) Unlock(name string) error {
+	delete(l.Extensions, name)
+	return l.Save()
+}
@@ -46,6 +46,7 @@ type Extension struct {
 	Root        *url.URL `json:"-" yaml:"-"`
 	Env         []string `json:"env,omitempty" yaml:"env,omitempty"`
 
+	Preferences  []Preference           `json:"preferences,omitempty" yaml:"preferences,omitempty"`
 	Requirements []ExtensionRequirement `json:"requirements,omitempty" yaml:"requirements,omitempty"`
 	RootItems    []RootItem             `json:"rootItems" yaml:"rootItems"`
 	Commands     map[string]Command     `json:"commands"`
@@ -65,6 +66,7 @@ func (r ExtensionRequirement) Check() bool {
 
 var ExtensionSchema *jsonschema.Schema
 var PageSchema *jsonschema.Schema
+var ListItemSchema *jsonschema.Schema
 
 func init() {
 	var err error
@@ -96,6 +98,11 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
+
+	ListItemSchema, err = compiler.Compile("https://pomdtr.github.io/sunbeam/schemas/page.json#/$defs/listitem")
+	if err != nil {
+		panic(err)
+	}
 }
 
 func (api *Api) LoadExtensions(extensionRoot string) error {
@@ -5,8 +5,12 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
+	"text/template"
+	"time"
 
 	"github.com/pomdtr/sunbeam/app"
+	"github.com/pomdtr/sunbeam/utils"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 )
@@ -46,6 +50,37 @@ func NewCmdCheck() *cobra.Command {
 				return fmt.Errorf("failed to unmarshal manifest: %w", err)
 			}
 
+			for commandName, command := range extension.Commands {
+				// Parsing fails if a template references an undeclared param
+				funcMap := template.FuncMap{
+					"query": func() string { return "" },
+				}
+				for _, param := range command.Params {
+					funcMap[strings.Replace(param.Name, "-", "_", -1)] = func() string { return "" }
+				}
+				for _, tmpl := range command.Exec.Templates() {
+					if _, err := utils.RenderString(tmpl, funcMap); err != nil {
+						return fmt.Errorf("command '%s' has an invalid exec template: %w", commandName, err)
+					}
+				}
+
+				if command.Timeout != "" {
+					if _, err := time.ParseDuration(command.Timeout); err != nil {
+						return fmt.Errorf("command '%s' has an invalid timeout: %w", commandName, err)
+					}
+				}
+
+				for _, param := range command.Params {
+					if param.Default == nil {
+						continue
+					}
+
+					if _, err := param.Convert(param.Default); err != nil {
+						return fmt.Errorf("command '%s' has an invalid default for param '%s': %w", commandName, param.Name, err)
+					}
+				}
+			}
+
 			for _, rootItem := range extension.RootItems {
 				if _, ok := extension.Commands[rootItem.Command]; !ok {
 					return fmt.Errorf("root item '%s' references unknown command '%s'", rootItem.Title, rootItem.Command)
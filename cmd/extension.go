@@ -3,14 +3,19 @@ package cmd
 import (
 	"fmt"
 	"io/fs"
+	"net/url"
 	"os"
 	"os/exec"
 	"path"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-isatty"
 	"github.com/olekukonko/tablewriter"
 	"github.com/otiai10/copy"
 	"github.com/pomdtr/sunbeam/app"
@@ -33,10 +38,15 @@ func NewCmdExtension(api app.Api, config *tui.Config) *cobra.Command {
 	}
 
 	extensionCommand.AddCommand(func() *cobra.Command {
-		return &cobra.Command{
+		command := &cobra.Command{
 			Use:   "install <name> <directory-or-url>",
-			Short: "Install a sunbeam extension from a local directory or a git repository",
-			Args:  cobra.ExactArgs(2),
+			Short: "Install a sunbeam extension from a local directory, a git repository or an archive",
+			Long: `Install a sunbeam extension from a local directory, a git repository or an archive.
+
+Use the url#path/to/extension@ref syntax, or the --path and --ref flags, to install an extension located in a subdirectory of a repository.
+
+Archives can be .tar.gz or .zip files, given as a local path or as a file:// or http(s):// url.`,
+			Args: cobra.ExactArgs(2),
 			PreRunE: func(cmd *cobra.Command, args []string) error {
 				extensionName := args[0]
 				invalidNames := []string{"extension", "check", "query", "run", "serve"}
@@ -59,64 +69,72 @@ func NewCmdExtension(api app.Api, config *tui.Config) *cobra.Command {
 			},
 			RunE: func(cmd *cobra.Command, args []string) error {
 				extensionName := args[0]
-				extensionRoot := args[1]
+				source := args[1]
 				targetDir := path.Join(api.ExtensionRoot, extensionName)
-				if _, err := os.Stat(targetDir); err == nil {
+				if _, err := os.Lstat(targetDir); err == nil {
 					return fmt.Errorf("extension %s is already installed at %s", extensionName, targetDir)
 				}
 
-				if _, err := os.Stat(extensionRoot); err == nil {
-					extensionRoot, err = filepath.Abs(extensionRoot)
-					if err != nil {
-						fmt.Fprintf(os.Stderr, "Failed to get absolute path for extension root: %s", err)
-						os.Exit(1)
-					}
-
-					if _, err = os.Stat(path.Join(extensionRoot, "sunbeam.yml")); os.IsNotExist(err) {
-						return fmt.Errorf("current directory is not a sunbeam extension")
-					}
-
-					symlinkTarget := path.Join(api.ExtensionRoot, extensionName)
-
-					if err := os.Symlink(extensionRoot, symlinkTarget); err != nil {
-						fmt.Fprintln(os.Stderr, "Failed to create symlink", err)
-						os.Exit(1)
-					}
-
-					fmt.Println("Installed extension", extensionName)
-					return nil
-				}
-
-				tmpDir, err := os.MkdirTemp(os.TempDir(), "sunbeam")
+				lockFile, err := app.LoadLockFile(api.LockFilePath())
 				if err != nil {
 					return err
 				}
 
-				err = utils.GitClone(extensionRoot, tmpDir)
-				if err != nil {
-					return err
-				}
+				var locked app.LockedExtension
+				if utils.IsArchive(source) {
+					// Archives are not versioned, the ref would be recorded in the lockfile without being used
+					if cmd.Flags().Changed("ref") || utils.ParseGitSource(source).Ref != "" {
+						return fmt.Errorf("a ref can only be set for git repositories")
+					}
 
-				manifestPath := path.Join(tmpDir, "sunbeam.yml")
-				if _, err = os.Stat(manifestPath); os.IsNotExist(err) {
-					return fmt.Errorf("extension %s does not have a sunbeam.yml manifest", extensionName)
-				}
+					if _, err := os.Stat(source); err == nil {
+						if source, err = filepath.Abs(source); err != nil {
+							return err
+						}
+					}
 
-				extension, err := app.ParseManifest(manifestPath)
-				if err != nil {
-					return err
-				}
+					subdir, _ := cmd.Flags().GetString("path")
+					checksum, _ := cmd.Flags().GetString("sha256")
+					locked, err = InstallArchiveExtension(app.LockedExtension{
+						Source: source,
+						Path:   subdir,
+						Sha256: checksum,
+					}, targetDir)
+					if err != nil {
+						return err
+					}
+				} else if cmd.Flags().Changed("sha256") {
+					return fmt.Errorf("a checksum can only be set for archives")
+				} else if _, err := os.Stat(source); err == nil {
+					if subdir, _ := cmd.Flags().GetString("path"); subdir != "" {
+						source = path.Join(source, subdir)
+					}
 
-				if err := PostInstallHook(extension); err != nil {
-					return err
-				}
+					locked, err = InstallLocalExtension(source, targetDir)
+					if err != nil {
+						return err
+					}
+				} else {
+					gitSource := utils.ParseGitSource(source)
+					if cmd.Flags().Changed("path") {
+						gitSource.Path, _ = cmd.Flags().GetString("path")
+					}
+					if cmd.Flags().Changed("ref") {
+						gitSource.Ref, _ = cmd.Flags().GetString("ref")
+					}
 
-				os.MkdirAll(path.Dir(targetDir), 0755)
-				if err := copy.Copy(tmpDir, targetDir); err != nil {
-					return err
+					locked, err = InstallGitExtension(app.LockedExtension{
+						Source: gitSource.Url,
+						Path:   gitSource.Path,
+						Ref:    gitSource.Ref,
+					}, targetDir)
+					if err != nil {
+						return err
+					}
 				}
 
-				if err := os.RemoveAll(tmpDir); err != nil {
+				locked.Name = extensionName
+				if err := lockFile.Lock(locked); err != nil {
 					return err
 				}
 
@@ -124,10 +142,15 @@ func NewCmdExtension(api app.Api, config *tui.Config) *cobra.Command {
 				return nil
 			},
 		}
+
+		command.Flags().String("path", "", "Subdirectory of the repository or archive containing the extension")
+		command.Flags().String("sha256", "", "Expected sha256 checksum of the archive")
+		command.Flags().String("ref", "", "Branch or tag of the repository to install")
+		return command
 	}())
 
 	extensionCommand.AddCommand(func() *cobra.Command {
-		return &cobra.Command{
+		command := &cobra.Command{
 			Use:       "remove",
 			ValidArgs: extensionArgs,
 			Short:     "Remove an installed extension",
@@ -138,15 +161,41 @@ func NewCmdExtension(api app.Api, config *tui.Config) *cobra.Command {
 					os.Exit(1)
 				}
 
+				if yes, _ := cmd.Flags().GetBool("yes"); !yes {
+					if !isatty.IsTerminal(os.Stdin.Fd()) {
+						return fmt.Errorf("cannot ask for confirmation, use --yes to remove the extension")
+					}
+
+					confirmed, err := tui.AskConfirmation(fmt.Sprintf("Remove %s?", args[0]), "The extension will be deleted from your computer.")
+					if err != nil {
+						return err
+					}
+					if !confirmed {
+						return nil
+					}
+				}
+
 				if err := os.RemoveAll(extensionPath); err != nil {
 					fmt.Fprintln(os.Stderr, "Failed to remove extension")
 					os.Exit(1)
 				}
 
+				lockFile, err := app.LoadLockFile(api.LockFilePath())
+				if err != nil {
+					return err
+				}
+
+				if err := lockFile.Unlock(args[0]); err != nil {
+					return err
+				}
+
 				fmt.Println("Removed extension", args[0])
 				return nil
 			},
 		}
+
+		command.Flags().BoolP("yes", "y", false, "Remove the extension without asking for confirmation")
+		return command
 	}())
 
 	extensionCommand.AddCommand(func() *cobra.Command {
@@ -173,6 +222,19 @@ func NewCmdExtension(api app.Api, config *tui.Config) *cobra.Command {
 					return fmt.Errorf("failed to remove old extension: %s", err)
 				}
 
+				lockFile, err := app.LoadLockFile(api.LockFilePath())
+				if err != nil {
+					return err
+				}
+
+				if locked, ok := lockFile.Extensions[args[0]]; ok {
+					delete(lockFile.Extensions, args[0])
+					locked.Name = args[1]
+					if err := lockFile.Lock(locked); err != nil {
+						return err
+					}
+				}
+
 				return nil
 			},
 		}
@@ -180,59 +242,190 @@ func NewCmdExtension(api app.Api, config *tui.Config) *cobra.Command {
 
 	extensionCommand.AddCommand(func() *cobra.Command {
 		command := &cobra.Command{
-			Use:       "upgrade",
+			Use:       "upgrade [extension]",
 			Short:     "Upgrade installed extension",
-			Args:      cobra.ExactArgs(1),
+			Args:      cobra.MaximumNArgs(1),
 			ValidArgs: extensionArgs,
 			RunE: func(cmd *cobra.Command, args []string) error {
-				extensionDir := path.Join(api.ExtensionRoot, args[0])
-				fi, err := os.Lstat(extensionDir)
-				if os.IsNotExist(err) {
-					fmt.Fprintln(os.Stderr, "Extension not found")
-					os.Exit(1)
+				all, _ := cmd.Flags().GetBool("all")
+				dryRun, _ := cmd.Flags().GetBool("dry-run")
+				ref, _ := cmd.Flags().GetString("ref")
+
+				lockFile, err := app.LoadLockFile(api.LockFilePath())
+				if err != nil {
+					return err
 				}
 
-				if IsLocalExtension(fi) {
-					return fmt.Errorf("cannot upgrade local extensions")
+				var extensionNames []string
+				if all {
+					if len(args) > 0 {
+						return fmt.Errorf("cannot specify an extension when using --all")
+					}
+
+					if ref != "" {
+						return fmt.Errorf("cannot specify a ref when using --all")
+					}
+
+					entries, err := os.ReadDir(api.ExtensionRoot)
+					if err != nil {
+						return fmt.Errorf("failed to read extension root: %w", err)
+					}
+
+					for _, entry := range entries {
+						fi, err := os.Lstat(path.Join(api.ExtensionRoot, entry.Name()))
+						if err != nil || IsLocalExtension(fi) || !fi.IsDir() {
+							continue
+						}
+
+						if locked, ok := lockFile.Extensions[entry.Name()]; ok && locked.IsArchive() {
+							continue
+						}
+
+						extensionNames = append(extensionNames, entry.Name())
+					}
+				} else {
+					if len(args) == 0 {
+						return fmt.Errorf("specify an extension to upgrade, or use --all")
+					}
+
+					fi, err := os.Lstat(path.Join(api.ExtensionRoot, args[0]))
+					if os.IsNotExist(err) {
+						return fmt.Errorf("extension %s is not installed", args[0])
+					} else if err != nil {
+						return err
+					}
+
+					if IsLocalExtension(fi) {
+						return fmt.Errorf("cannot upgrade local extensions")
+					}
+
+					if locked, ok := lockFile.Extensions[args[0]]; ok && locked.IsArchive() {
+						return fmt.Errorf("cannot upgrade extensions installed from an archive, reinstall them instead")
+					}
+
+					extensionNames = []string{args[0]}
 				}
 
-				gc := utils.NewGitClient(extensionDir)
+				rows := make([][]string, 0, len(extensionNames))
+				failures := 0
+				for _, extensionName := range extensionNames {
+					extensionDir := path.Join(api.ExtensionRoot, extensionName)
 
-				currentVersion := gc.GetCurrentVersion()
-				latestVersion, err := gc.GetLatestVersion()
-				if err != nil {
-					return err
+					var currentVersion, latestVersion string
+					if locked := lockFile.Extensions[extensionName]; locked.Path != "" {
+						currentVersion, latestVersion, err = UpgradeSubdirectoryExtension(lockFile, locked, extensionDir, ref, dryRun)
+					} else {
+						currentVersion, latestVersion, err = UpgradeExtension(locked, extensionDir, ref, dryRun)
+						if err == nil && !dryRun && currentVersion != latestVersion {
+							err = LockGitExtension(lockFile, extensionName, extensionDir, ref)
+						}
+					}
+
+					var status string
+					switch {
+					case err != nil:
+						failures++
+						status = fmt.Sprintf("failed: %s", err)
+					case currentVersion == latestVersion:
+						status = "up to date"
+					case dryRun:
+						status = "upgrade available"
+					default:
+						status = "upgraded"
+					}
+
+					rows = append(rows, []string{extensionName, shortSha(currentVersion), shortSha(latestVersion), status})
 				}
 
-				if currentVersion == latestVersion {
-					fmt.Printf("Extension %s is already up to date", args[0])
-					return nil
+				writer := tablewriter.NewWriter(os.Stdout)
+				writer.SetHeader([]string{"Extension", "Current", "Latest", "Status"})
+				writer.SetBorder(false)
+				writer.SetColumnSeparator(" ")
+				writer.AppendBulk(rows)
+				writer.Render()
+
+				if failures > 0 {
+					return fmt.Errorf("failed to upgrade %d extension(s)", failures)
 				}
 
-				if err := gc.Pull(); err != nil {
+				return nil
+			},
+		}
+
+		command.Flags().Bool("all", false, "Upgrade all installed extensions")
+		command.Flags().Bool("dry-run", false, "Only dispay what would be upgraded")
+		command.Flags().String("ref", "", "Branch or tag to upgrade to, defaults to the latest tag or the default branch")
+		return command
+	}())
+
+	extensionCommand.AddCommand(func() *cobra.Command {
+		command := &cobra.Command{
+			Use:   "sync",
+			Short: "Install the extensions recorded in the lock file at their pinned commits",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				prune, _ := cmd.Flags().GetBool("prune")
+
+				lockFile, err := app.LoadLockFile(api.LockFilePath())
+				if err != nil {
 					return err
 				}
 
-				manifestPath := path.Join(extensionDir, "sunbeam.yml")
-				if _, err = os.Stat(manifestPath); os.IsNotExist(err) {
-					return fmt.Errorf("extension %s does not have a sunbeam.yml manifest", args[0])
+				extensionNames := make([]string, 0, len(lockFile.Extensions))
+				for name := range lockFile.Extensions {
+					extensionNames = append(extensionNames, name)
 				}
+				sort.Strings(extensionNames)
 
-				extension, err := app.ParseManifest(manifestPath)
-				if err != nil {
-					return fmt.Errorf("failed to parse manifest: %w", err)
+				rows := make([][]string, 0, len(extensionNames))
+				failures := 0
+				for _, extensionName := range extensionNames {
+					locked := lockFile.Extensions[extensionName]
+					status, err := SyncExtension(locked, path.Join(api.ExtensionRoot, extensionName))
+					if err != nil {
+						failures++
+						status = fmt.Sprintf("failed: %s", err)
+					}
+
+					rows = append(rows, []string{extensionName, shortSha(locked.Commit), status})
 				}
 
-				if err := PostInstallHook(extension); err != nil {
-					return err
+				if prune {
+					entries, err := os.ReadDir(api.ExtensionRoot)
+					if err != nil {
+						return fmt.Errorf("failed to read extension root: %w", err)
+					}
+
+					for _, entry := range entries {
+						if _, ok := lockFile.Extensions[entry.Name()]; ok {
+							continue
+						}
+
+						status := "removed"
+						if err := os.RemoveAll(path.Join(api.ExtensionRoot, entry.Name())); err != nil {
+							failures++
+							status = fmt.Sprintf("failed: %s", err)
+						}
+						rows = append(rows, []string{entry.Name(), "", status})
+					}
+				}
+
+				writer := tablewriter.NewWriter(os.Stdout)
+				writer.SetHeader([]string{"Extension", "Commit", "Status"})
+				writer.SetBorder(false)
+				writer.SetColumnSeparator(" ")
+				writer.AppendBulk(rows)
+				writer.Render()
+
+				if failures > 0 {
+					return fmt.Errorf("failed to sync %d extension(s)", failures)
 				}
 
 				return nil
 			},
 		}
 
-		command.Flags().Bool("all", false, "Upgrade all installed extensions")
-		command.Flags().Bool("dry-run", false, "Only dispay what would be upgraded")
+		command.Flags().Bool("prune", false, "Remove installed extensions missing from the lock file")
 		return command
 	}())
 
@@ -299,10 +492,10 @@ func NewCmdExtension(api app.Api, config *tui.Config) *cobra.Command {
 						item.Actions = []tui.Action{
 							{
 								Title: "Remove Extension",
-								Cmd: func() tea.Msg {
-									exec.Command("sunbeam", "extension", "remove", repo.FullName).Run()
+								Cmd: tui.NewConfirmCmd(fmt.Sprintf("Remove %s?", repo.FullName), "The extension will be deleted from your computer.", func() tea.Msg {
+									exec.Command("sunbeam", "extension", "remove", "--yes", repo.FullName).Run()
 									return tea.Quit()
-								},
+								}),
 							},
 							{
 								Title: "Open in Browser",
@@ -347,6 +540,368 @@ func IsLocalExtension(fi fs.FileInfo) bool {
 	return fi.Mode()&os.ModeSymlink != 0
 }
 
+// UpgradeExtension checks out the given ref of the extension installed in extensionDir and runs its post install hook.
+// If ref is empty, the ref recorded in the lock file is used, falling back to the ref the checkout is following.
+// When dryRun is set, the versions are only compared.
+func UpgradeExtension(locked app.LockedExtension, extensionDir string, ref string, dryRun bool) (currentVersion string, latestVersion string, err error) {
+	gc := utils.NewGitClient(extensionDir)
+
+	currentVersion = gc.GetCurrentVersion()
+	if ref == "" && locked.Ref != "" {
+		ref, err = latestLockedRef(gc, locked.Ref)
+		if err != nil {
+			return currentVersion, "", fmt.Errorf("failed to fetch latest version: %w", err)
+		}
+	} else if ref == "" {
+		ref, err = gc.GetLatestRef()
+		if err != nil {
+			return currentVersion, "", fmt.Errorf("failed to fetch latest version: %w", err)
+		}
+	}
+
+	latestVersion, err = gc.ResolveRef(ref)
+	if err != nil {
+		return currentVersion, "", fmt.Errorf("failed to fetch latest version: %w", err)
+	}
+
+	if dryRun || currentVersion == latestVersion {
+		return currentVersion, latestVersion, nil
+	}
+
+	if err := gc.Checkout(ref); err != nil {
+		return currentVersion, latestVersion, fmt.Errorf("failed to checkout %s: %w", ref, err)
+	}
+
+	if _, err := SetupExtension(extensionDir); err != nil {
+		return currentVersion, latestVersion, err
+	}
+
+	return currentVersion, latestVersion, nil
+}
+
+// UpgradeSubdirectoryExtension clones the repository of an extension installed from a subdirectory,
+// and copies the subdirectory at the given ref to extensionDir.
+// If ref is empty, the ref recorded in the lock file is used, falling back to the latest tag or the default branch.
+func UpgradeSubdirectoryExtension(lockFile *app.LockFile, locked app.LockedExtension, extensionDir string, ref string, dryRun bool) (currentVersion string, latestVersion string, err error) {
+	gc := utils.NewRemoteGitClient(locked.Source)
+
+	currentVersion = locked.Commit
+	explicitRef := ref
+	if ref == "" {
+		ref, err = latestLockedRef(gc, locked.Ref)
+		if err != nil {
+			return currentVersion, "", fmt.Errorf("failed to fetch latest version: %w", err)
+		}
+	}
+
+	latestVersion, err = gc.ResolveRef(ref)
+	if err != nil {
+		return currentVersion, "", fmt.Errorf("failed to fetch latest version: %w", err)
+	}
+
+	if dryRun || currentVersion == latestVersion {
+		return currentVersion, latestVersion, nil
+	}
+
+	// Like for other git extensions, an explicit ref is always recorded
+	if explicitRef != "" || locked.Ref != "" {
+		locked.Ref = ref
+	}
+	locked.Commit = latestVersion
+
+	upgraded, err := ReplaceExtension(locked, extensionDir)
+	if err != nil {
+		return currentVersion, latestVersion, err
+	}
+
+	return currentVersion, latestVersion, lockFile.Lock(upgraded)
+}
+
+// latestLockedRef returns the highest tag if the locked ref is a semver tag, the locked ref otherwise.
+func latestLockedRef(gc *utils.GitClient, lockedRef string) (string, error) {
+	if utils.IsSemverTag(lockedRef) {
+		tag, err := gc.GetLatestTag()
+		if err != nil {
+			return "", err
+		}
+		if tag != "" {
+			return tag, nil
+		}
+	}
+
+	if lockedRef != "" {
+		return lockedRef, nil
+	}
+
+	return gc.GetDefaultBranch()
+}
+
+// ReplaceExtension installs the locked extension in a staging directory, then swaps it with the one in extensionDir.
+func ReplaceExtension(locked app.LockedExtension, extensionDir string) (app.LockedExtension, error) {
+	stagingDir, err := os.MkdirTemp(os.TempDir(), "sunbeam")
+	if err != nil {
+		return app.LockedExtension{}, err
+	}
+	defer os.RemoveAll(stagingDir)
+
+	installed, err := InstallGitExtension(locked, path.Join(stagingDir, "extension"))
+	if err != nil {
+		return app.LockedExtension{}, err
+	}
+	installed.Name = locked.Name
+
+	if err := os.RemoveAll(extensionDir); err != nil {
+		return app.LockedExtension{}, err
+	}
+
+	if err := copy.Copy(path.Join(stagingDir, "extension"), extensionDir); err != nil {
+		return app.LockedExtension{}, err
+	}
+
+	return installed, nil
+}
+
+// SetupExtension validates the manifest of the extension located in extensionDir and runs its post install hook.
+func SetupExtension(extensionDir string) (app.Extension, error) {
+	manifestPath := path.Join(extensionDir, "sunbeam.yml")
+	if _, err := os.Stat(manifestPath); os.IsNotExist(err) {
+		return app.Extension{}, fmt.Errorf("extension does not have a sunbeam.yml manifest")
+	}
+
+	extension, err := app.ParseManifest(manifestPath)
+	if err != nil {
+		return app.Extension{}, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	extension.Root = &url.URL{
+		Scheme: "file",
+		Path:   extensionDir,
+	}
+
+	if err := PostInstallHook(extension); err != nil {
+		return app.Extension{}, fmt.Errorf("post install hook failed: %w", err)
+	}
+
+	return extension, nil
+}
+
+// SyncExtension installs the locked extension to extensionDir, or checks out its pinned commit if it is already installed.
+func SyncExtension(locked app.LockedExtension, extensionDir string) (status string, err error) {
+	fi, err := os.Lstat(extensionDir)
+	if os.IsNotExist(err) {
+		if locked.IsLocal() {
+			_, err = InstallLocalExtension(locked.Source, extensionDir)
+		} else if locked.IsArchive() {
+			_, err = InstallArchiveExtension(locked, extensionDir)
+		} else {
+			_, err = InstallGitExtension(locked, extensionDir)
+		}
+		if err != nil {
+			return "", err
+		}
+		return "installed", nil
+	} else if err != nil {
+		return "", err
+	}
+
+	if IsLocalExtension(fi) || locked.IsLocal() {
+		return "local", nil
+	}
+
+	if locked.IsArchive() {
+		return "up to date", nil
+	}
+
+	// Extensions installed from a subdirectory are not git repositories, reinstall them at the pinned commit
+	if locked.Path != "" {
+		if _, err := ReplaceExtension(locked, extensionDir); err != nil {
+			return "", err
+		}
+		return "reinstalled", nil
+	}
+
+	gc := utils.NewGitClient(extensionDir)
+	if locked.Commit == "" || gc.GetCurrentVersion() == locked.Commit {
+		return "up to date", nil
+	}
+
+	if err := gc.CheckoutCommit(locked.Commit); err != nil {
+		return "", err
+	}
+
+	if _, err := SetupExtension(extensionDir); err != nil {
+		return "", err
+	}
+
+	return "synced", nil
+}
+
+// LockGitExtension records the current commit of the git extension located in extensionDir in the lock file.
+// The ref is only updated if it is not empty.
+func LockGitExtension(lockFile *app.LockFile, name string, extensionDir string, ref string) error {
+	extension, err := app.ParseManifest(path.Join(extensionDir, "sunbeam.yml"))
+	if err != nil {
+		return fmt.Errorf("failed to parse manifest: %w", err)
+	}
+
+	gc := utils.NewGitClient(extensionDir)
+	locked := lockFile.Extensions[name]
+	locked.Name = name
+	locked.Source = gc.GetOrigin()
+	locked.Commit = gc.GetCurrentVersion()
+	locked.Version = extension.Version
+	locked.InstalledAt = time.Now()
+	if ref != "" {
+		locked.Ref = ref
+	}
+
+	return lockFile.Lock(locked)
+}
+
+// InstallLocalExtension symlinks the extension located in extensionRoot to targetDir.
+func InstallLocalExtension(extensionRoot string, targetDir string) (app.LockedExtension, error) {
+	extensionRoot, err := filepath.Abs(extensionRoot)
+	if err != nil {
+		return app.LockedExtension{}, fmt.Errorf("failed to get absolute path for extension root: %w", err)
+	}
+
+	manifestPath := path.Join(extensionRoot, "sunbeam.yml")
+	if _, err = os.Stat(manifestPath); os.IsNotExist(err) {
+		return app.LockedExtension{}, fmt.Errorf("directory %s is not a sunbeam extension", extensionRoot)
+	}
+
+	extension, err := app.ParseManifest(manifestPath)
+	if err != nil {
+		return app.LockedExtension{}, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+
+	if err := os.MkdirAll(path.Dir(targetDir), 0755); err != nil {
+		return app.LockedExtension{}, err
+	}
+
+	if err := os.Symlink(extensionRoot, targetDir); err != nil {
+		return app.LockedExtension{}, fmt.Errorf("failed to create symlink: %w", err)
+	}
+
+	return app.LockedExtension{
+		Source:      extensionRoot,
+		Version:     extension.Version,
+		InstalledAt: time.Now(),
+	}, nil
+}
+
+// InstallGitExtension clones the repository of the locked extension and copies it to targetDir.
+// The pinned commit is checked out if there is one, the ref otherwise.
+// If the extension is located in a subdirectory of the repository, only this subdirectory is copied.
+func InstallGitExtension(locked app.LockedExtension, targetDir string) (app.LockedExtension, error) {
+	tmpDir, err := os.MkdirTemp(os.TempDir(), "sunbeam")
+	if err != nil {
+		return app.LockedExtension{}, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := utils.GitClone(locked.Source, tmpDir); err != nil {
+		return app.LockedExtension{}, err
+	}
+
+	gc := utils.NewGitClient(tmpDir)
+	if locked.Commit != "" {
+		if err := gc.CheckoutCommit(locked.Commit); err != nil {
+			return app.LockedExtension{}, err
+		}
+	} else if locked.Ref != "" {
+		if err := gc.Checkout(locked.Ref); err != nil {
+			return app.LockedExtension{}, fmt.Errorf("failed to checkout %s: %w", locked.Ref, err)
+		}
+	}
+
+	extensionDir := filepath.Join(tmpDir, filepath.FromSlash(locked.Path))
+	if rel, err := filepath.Rel(tmpDir, extensionDir); err != nil || strings.HasPrefix(rel, "..") {
+		return app.LockedExtension{}, fmt.Errorf("path %s is outside of the repository", locked.Path)
+	}
+
+	extension, err := SetupExtension(extensionDir)
+	if err != nil {
+		return app.LockedExtension{}, err
+	}
+
+	if err := os.MkdirAll(path.Dir(targetDir), 0755); err != nil {
+		return app.LockedExtension{}, err
+	}
+
+	if err := copy.Copy(extensionDir, targetDir); err != nil {
+		return app.LockedExtension{}, err
+	}
+
+	locked.Commit = gc.GetCurrentVersion()
+	locked.Version = extension.Version
+	locked.InstalledAt = time.Now()
+
+	return locked, nil
+}
+
+// InstallArchiveExtension downloads and extracts the archive of the locked extension, then copies it to targetDir.
+// If the locked extension has a checksum, the archive must match it.
+func InstallArchiveExtension(locked app.LockedExtension, targetDir string) (app.LockedExtension, error) {
+	archivePath, checksum, err := utils.DownloadArchive(locked.Source)
+	if err != nil {
+		return app.LockedExtension{}, fmt.Errorf("failed to download archive: %w", err)
+	}
+	defer os.Remove(archivePath)
+
+	if locked.Sha256 != "" && !strings.EqualFold(locked.Sha256, checksum) {
+		return app.LockedExtension{}, fmt.Errorf("checksum mismatch: expected %s, got %s", locked.Sha256, checksum)
+	}
+
+	tmpDir, err := os.MkdirTemp(os.TempDir(), "sunbeam")
+	if err != nil {
+		return app.LockedExtension{}, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := utils.ExtractArchive(archivePath, tmpDir); err != nil {
+		return app.LockedExtension{}, fmt.Errorf("failed to extract archive: %w", err)
+	}
+
+	extensionDir := filepath.Join(tmpDir, filepath.FromSlash(locked.Path))
+	if rel, err := filepath.Rel(tmpDir, extensionDir); err != nil || strings.HasPrefix(rel, "..") {
+		return app.LockedExtension{}, fmt.Errorf("path %s is outside of the archive", locked.Path)
+	}
+
+	// Archives generated by code forges wrap their content in a single top-level directory
+	if _, err := os.Stat(filepath.Join(extensionDir, "sunbeam.yml")); os.IsNotExist(err) {
+		entries, err := os.ReadDir(extensionDir)
+		if err == nil && len(entries) == 1 && entries[0].IsDir() {
+			extensionDir = filepath.Join(extensionDir, entries[0].Name())
+		}
+	}
+
+	extension, err := SetupExtension(extensionDir)
+	if err != nil {
+		return app.LockedExtension{}, err
+	}
+
+	if err := os.MkdirAll(path.Dir(targetDir), 0755); err != nil {
+		return app.LockedExtension{}, err
+	}
+
+	if err := copy.Copy(extensionDir, targetDir); err != nil {
+		return app.LockedExtension{}, err
+	}
+
+	locked.Sha256 = checksum
+	locked.Version = extension.Version
+	locked.InstalledAt = time.Now()
+
+	return locked, nil
+}
+
+func shortSha(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
 func PostInstallHook(extension app.Extension) error {
 	if extension.PostInstall == "" {
 		return nil
@@ -1,9 +1,15 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
+	"os/signal"
 	"path"
+	"syscall"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
@@ -29,6 +35,18 @@ func Execute(version string) (err error) {
 		if err := yaml.Unmarshal(bytes, &config); err != nil {
 			return fmt.Errorf("failed to parse config file: %w", err)
 		}
+		if err := tui.SetPreviewConfig(config.Preview); err != nil {
+			return fmt.Errorf("invalid config file: %w", err)
+		}
+		if err := tui.SetMatchMode(config.MatchMode); err != nil {
+			return fmt.Errorf("invalid config file: %w", err)
+		}
+		tui.SetOpenCommand(config.OpenCommand)
+	}
+
+	// The clipboard is set even if there is no config file, to detect the backend from the environment
+	if err := tui.SetClipboardConfig(config.Clipboard); err != nil {
+		return fmt.Errorf("invalid config file: %w", err)
 	}
 
 	extensionRoot := path.Join(homeDir, ".local", "share", "sunbeam", "extensions")
@@ -104,28 +122,49 @@ func NewExtensionCommand(name string, extension app.Extension, config *tui.Confi
 	for commandName, command := range extension.Commands {
 		commandName := commandName
 		command := command
+
+		hasOutputParam := false
+		for _, param := range command.Params {
+			if param.Name == "output" {
+				hasOutputParam = true
+			}
+		}
+
 		scriptCmd := &cobra.Command{
 			Use:   commandName,
 			Short: command.Description,
 			RunE: func(cmd *cobra.Command, args []string) (err error) {
+				var outputFormat string
+				if !hasOutputParam {
+					outputFormat, _ = cmd.Flags().GetString("output")
+				}
+
 				with := make(map[string]app.CommandInput)
+				params := make(map[string]any)
 				for _, param := range command.Params {
+					var value any
 					switch param.Type {
 					case "boolean":
-						value, err := cmd.Flags().GetBool(param.Name)
-						if err != nil {
-							return err
-						}
-						with[param.Name] = app.CommandInput{Value: value}
+						value, err = cmd.Flags().GetBool(param.Name)
+					case "number":
+						value, err = cmd.Flags().GetFloat64(param.Name)
+					case "integer":
+						value, err = cmd.Flags().GetInt(param.Name)
 					default:
-						value, err := cmd.Flags().GetString(param.Name)
-						if err != nil {
-							return err
-						}
-						with[param.Name] = app.CommandInput{Value: value}
+						value, err = cmd.Flags().GetString(param.Name)
+					}
+					if err != nil {
+						return err
 					}
 
+					with[param.Name] = app.CommandInput{Value: value}
+					params[param.Name] = value
 				}
+
+				if _, err := command.ValidateParams(params); err != nil {
+					return err
+				}
+
 				runner := tui.NewCommandRunner(
 					tui.NamedExtension{
 						Name:      name,
@@ -138,6 +177,15 @@ func NewExtensionCommand(name string, extension app.Extension, config *tui.Confi
 					with,
 				)
 
+				if outputFormat != "" {
+					err := RunNonInteractive(runner, extension, command, with, outputFormat)
+					// The command already reported its failure on stderr
+					if errors.As(err, new(ExitCodeError)) {
+						cmd.SilenceErrors = true
+					}
+					return err
+				}
+
 				model := tui.NewModel(runner)
 
 				err = tui.Draw(model, true)
@@ -148,24 +196,41 @@ func NewExtensionCommand(name string, extension app.Extension, config *tui.Confi
 			},
 		}
 
+		if !hasOutputParam {
+			scriptCmd.Flags().StringP("output", "o", "", "Run the command without the UI and print its output, one of: json, raw")
+			scriptCmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+				return []string{"json", "raw"}, cobra.ShellCompDirectiveNoFileComp
+			})
+		}
+
 		for _, param := range command.Params {
+			param := param
+
+			// Invalid defaults are reported by sunbeam check manifest, the flag is required instead
+			var defaultValue any
+			if param.Default != nil {
+				if value, err := param.Convert(param.Default); err == nil {
+					defaultValue = value
+				}
+			}
+
 			switch param.Type {
 			case "boolean":
-				if param.Default != nil {
-					defaultValue := param.Default.(bool)
-					scriptCmd.Flags().Bool(param.Name, defaultValue, param.Description)
-				} else {
-					scriptCmd.Flags().Bool(param.Name, false, param.Description)
-					scriptCmd.MarkFlagRequired(param.Name)
-				}
+				value, _ := defaultValue.(bool)
+				scriptCmd.Flags().Bool(param.Name, value, param.Description)
+			case "number":
+				value, _ := defaultValue.(float64)
+				scriptCmd.Flags().Float64(param.Name, value, param.Description)
+			case "integer":
+				value, _ := defaultValue.(int)
+				scriptCmd.Flags().Int(param.Name, value, param.Description)
 			default:
-				if param.Default != nil {
-					defaultValue := param.Default.(string)
-					scriptCmd.Flags().String(param.Name, defaultValue, param.Description)
-				} else {
-					scriptCmd.Flags().String(param.Name, "", param.Description)
-					scriptCmd.MarkFlagRequired(param.Name)
-				}
+				value, _ := defaultValue.(string)
+				scriptCmd.Flags().String(param.Name, value, param.Description)
+			}
+
+			if defaultValue == nil {
+				scriptCmd.MarkFlagRequired(param.Name)
 			}
 
 			if len(param.Enum) > 0 {
@@ -188,3 +253,108 @@ func NewExtensionCommand(name string, extension app.Extension, config *tui.Confi
 
 	return extensionCmd
 }
+
+// ExitCodeError is returned when sunbeam must exit with a specific code.
+type ExitCodeError int
+
+func (e ExitCodeError) Error() string {
+	return fmt.Sprintf("exit status %d", int(e))
+}
+
+// RunNonInteractive runs the command without the UI, and prints its output to stdout.
+// Push-page outputs are validated against the page schema when using the json format.
+// The items of streaming lists are merged into a single page.
+// If the command fails, an ExitCodeError holding its exit code is returned.
+func RunNonInteractive(runner *tui.CommandRunner, extension app.Extension, command app.Command, with map[string]app.CommandInput, outputFormat string) error {
+	if outputFormat != "json" && outputFormat != "raw" {
+		return fmt.Errorf("invalid output format: %s, expected json or raw", outputFormat)
+	}
+
+	if extension.Root.Scheme != "file" {
+		return fmt.Errorf("non-interactive mode is not supported for remote extensions")
+	}
+
+	if err := runner.CheckEnv(); err != nil {
+		return err
+	}
+
+	preferences, missing := runner.ResolvePreferences()
+	if len(missing) > 0 {
+		return fmt.Errorf("missing preference: %s, run the command interactively to set it", missing[0].Title)
+	}
+
+	params := make(map[string]any)
+	for name, input := range with {
+		params[name] = input.Value
+	}
+
+	cmd, err := command.Cmd(app.CommandParams{
+		With:        params,
+		Preferences: preferences,
+	}, extension.Root.Path)
+	if err != nil {
+		return err
+	}
+
+	cmd.Stderr = os.Stderr
+	if command.Interactive {
+		cmd.Stdin = os.Stdin
+	}
+
+	var output []byte
+	if command.Interactive {
+		// Interactive commands must stay in the foreground process group to read from the terminal
+		output, err = cmd.Output()
+	} else {
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+		ctx, cancel = command.WithTimeout(ctx)
+		defer cancel()
+
+		output, err = app.Output(ctx, cmd)
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("command timed out after %s", command.Timeout)
+		}
+		if ctx.Err() != nil {
+			return fmt.Errorf("command cancelled")
+		}
+	}
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			os.Stdout.Write(output)
+			return ExitCodeError(exitErr.ExitCode())
+		}
+		return err
+	}
+
+	if outputFormat == "raw" {
+		_, err := os.Stdout.Write(output)
+		return err
+	}
+
+	if command.OnSuccess != "push-page" {
+		return json.NewEncoder(os.Stdout).Encode(string(output))
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	if page, ok, err := tui.ParseListStream(output); ok {
+		if err != nil {
+			return fmt.Errorf("command output is not a valid page: %w", err)
+		}
+		return encoder.Encode(page)
+	}
+
+	var page any
+	if err := json.Unmarshal(output, &page); err != nil {
+		return fmt.Errorf("command output is not valid json: %w", err)
+	}
+
+	if err := app.PageSchema.Validate(page); err != nil {
+		return fmt.Errorf("command output is not a valid page: %w", err)
+	}
+
+	return encoder.Encode(page)
+}
@@ -16,7 +16,8 @@ bw --nointeraction list items | sunbeam query '.[] | {
             type: "copy-text",
             title: "Copy Login",
             shortcut: "ctrl+y",
-            text: "\(.login)"
+            text: "\(.login)",
+            exit: false
         }
     ]
 }' | sunbeam query --slurp '{
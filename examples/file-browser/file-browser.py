@@ -23,24 +23,40 @@ json.dump(
                 "title": path.name,
                 "accessories": [str(root.absolute())],
                 "actions": [
-                    (
-                        {
-                            "type": "open-url",
-                            "url": f"file://{path.absolute()}",
-                            "title": "Open File",
-                        }
+                    *(
+                        [
+                            {
+                                "type": "open-path",
+                                "path": str(path.absolute()),
+                                "title": "Open File",
+                            },
+                            {
+                                "type": "edit",
+                                "path": str(path.absolute()),
+                                "title": "Edit File",
+                                "shortcut": "ctrl+e",
+                            },
+                        ]
                         if path.is_file()
-                        else {
-                            "type": "run-command",
-                            "command": "browse-files",
-                            "title": "Browse Directory",
-                            "with": {"root": str(path.absolute())},
-                        }
+                        else [
+                            {
+                                "type": "run-command",
+                                "command": "browse-files",
+                                "title": "Browse Directory",
+                                "with": {"root": str(path.absolute())},
+                            },
+                            {
+                                "type": "open-path",
+                                "path": str(path.absolute()),
+                                "title": "Open Directory",
+                                "shortcut": "ctrl+o",
+                            },
+                        ]
                     ),
                     {
                         "type": "copy-text",
                         "title": "Copy Path",
-                        "shorcut": "ctrl+y",
+                        "shortcut": "ctrl+y",
                         "text": str(path.absolute()),
                     },
                     {
@@ -48,6 +64,7 @@ json.dump(
                         "title": "Delete File",
                         "shortcut": "ctrl+d",
                         "command": "delete-file",
+                        "confirm": True,
                         "with": {"path": str(path.absolute())},
                     },
                 ],
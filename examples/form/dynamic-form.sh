@@ -1,17 +1,33 @@
 #!/bin/bash
 
-sunbeam query --null-input '
-{
-    type: "form",
-    title: "Dynamic Form",
-    form: {
-        inputs: [
-            { name: "name", type: "textfield", title: "textfield" }
+# Each step of the wizard is a form page, submitting its values to the next step
+case "$1" in
+name)
+    sunbeam query --null-input '{
+        type: "form",
+        title: "Dynamic Form",
+        fields: [
+            { name: "name", type: "textfield", title: "Name" }
         ],
-        target: {
-            command: "hello"
-        }
-
-    }
-}
-'
+        submit: { command: "dynamic-form-color", onSuccess: "push-page" }
+    }'
+    ;;
+color)
+    sunbeam query --null-input --arg name="$2" '{
+        type: "form",
+        title: "Dynamic Form",
+        fields: [
+            { name: "color", type: "dropdown", title: "Favorite Color", choices: ["red", "green", "blue"] }
+        ],
+        submit: { command: "dynamic-form-result", with: { name: $name }, onSuccess: "push-page" }
+    }'
+    ;;
+result)
+    sunbeam query --null-input --arg name="$2" --arg color="$3" '{
+        type: "detail",
+        title: "Dynamic Form",
+        preview: "Hello \($name), your favorite color is \($color)!",
+        actions: []
+    }'
+    ;;
+esac
@@ -4,8 +4,10 @@ set -euo pipefail
 
 REPO=$1
 
+REPO_INFO=$(gh api "repos/$REPO")
+
 # shellcheck disable=SC2016
-gh api "repos/$REPO/readme" | sunbeam query --arg REPO="$REPO" '
+gh api "repos/$REPO/readme" | sunbeam query --arg REPO="$REPO" --argjson info="$REPO_INFO" '
 {
   type: "detail",
   preview: {
@@ -14,6 +16,13 @@ gh api "repos/$REPO/readme" | sunbeam query --arg REPO="$REPO" '
       url: "https://github.com/\($REPO)"
     }
   },
+  metadata: [
+    { label: "Stars", value: "\($info.stargazers_count)" },
+    { label: "Language", value: ($info.language // "None") },
+    { label: "License", value: ($info.license.spdx_id // "None") },
+    { label: "Topics", tags: [$info.topics[] | { text: ., color: "blue" }] },
+    { label: "Repository", value: $info.full_name, url: $info.html_url }
+  ],
   actions: [
     { type: "open-url", title: "Open in Browser", url: .html_url }
   ]
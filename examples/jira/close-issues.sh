@@ -0,0 +1,12 @@
+#!/bin/bash
+
+set -euo pipefail
+
+# The keys of the selected issues are passed on stdin, as a json array
+sunbeam query '.[]' | xargs -n1 | while read -r KEY; do
+    curl -X POST \
+        -H "Content-Type: application/json" \
+        -u "achille.lacoin@dailymotion.com:$JIRA_TOKEN" \
+        --data "{\"transition\": {\"id\": \"$JIRA_CLOSE_TRANSITION\"}}" \
+        "https://dailymotion.atlassian.net/rest/api/2/issue/$KEY/transitions"
+done
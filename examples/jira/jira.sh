@@ -7,12 +7,21 @@ curl -X GET \
     -u "achille.lacoin@dailymotion.com:$JIRA_TOKEN" \
     "https://dailymotion.atlassian.net/rest/api/2/search" |
 sunbeam query '.issues[] | {
+    id: .key,
     title: .fields.summary,
     subtitle: .key,
     actions: [
         {
-            type: "open",
-            target: "https://dailymotion.atlassian.net/browse/\(.key)"
+            type: "open-url",
+            url: "https://dailymotion.atlassian.net/browse/\(.key)"
+        },
+        {
+            type: "run-command",
+            title: "Close Selected Issues",
+            shortcut: "ctrl+d",
+            command: "close-issues",
+            onSuccess: "reload-page",
+            batch: true
         }
     ],
     accessories: [
@@ -20,6 +29,6 @@ sunbeam query '.issues[] | {
     ]
 }' | sunbeam query --slurp '{
     type: "list",
-    list: {items: .}
+    multiSelect: true,
+    items: .
 }'
-
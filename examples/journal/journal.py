@@ -71,6 +71,7 @@ def list_entries(journal: Journal):
                         "type": "run-command",
                         "title": "Delete Entry",
                         "command": "delete-entry",
+                        "confirm": True,
                         "onSuccess": "reload-page",
                         "shortcut": "ctrl+d",
                         "with": {"uuid": uuid},
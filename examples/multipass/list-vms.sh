@@ -4,6 +4,7 @@ set -euo pipefail
 
 multipass list --format json | sunbeam query '.list[] |
 {
+    id: .name,
     title: .name,
     subtitle: .release,
     preview: {
@@ -16,23 +17,27 @@ multipass list --format json | sunbeam query '.list[] |
       .state
     ],
     actions:
-      (
+      ((
         if
           .state == "Running"
         then
           [
             {type: "run-command", title: "Stop \(.name)", command: "stop-vm", onSuccess: "reload-page", with: {vm: .name}},
-            {type: "run-command", shortcut: "ctrl+s", title: "Open Shell", command: "open-shell", onSuccess: "reload-page", with: {vm: .name}}
+            {type: "run-command", shortcut: "ctrl+s", title: "Open Shell", command: "open-shell", onSuccess: "reload-page", with: {vm: .name}},
+            {type: "run-command", shortcut: "ctrl+t", title: "Stop Selected VMs", command: "stop-vms", onSuccess: "reload-page", batch: true}
           ]
         else
           [
             {type: "run-command", title: "Start \(.name)", command: "start-vm", onSuccess: "reload-page", with: {vm: .name}}
           ]
         end
-      ),
+      ) + [
+        {type: "run-command", shortcut: "ctrl+d", title: "Delete Selected VMs", command: "delete-vms", onSuccess: "reload-page", batch: true, confirm: {message: "The selected VMs will be deleted and purged."}}
+      ]),
 }
 ' | sunbeam query --slurp '{
     type: "list",
     showPreview: true,
+    multiSelect: true,
     items: .
 }'
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"os"
 
 	"github.com/pomdtr/sunbeam/cmd"
@@ -10,6 +11,10 @@ var version = "dev"
 
 func main() {
 	err := cmd.Execute(version)
+	var exitCode cmd.ExitCodeError
+	if errors.As(err, &exitCode) {
+		os.Exit(int(exitCode))
+	}
 	if err != nil {
 		os.Exit(1)
 	}
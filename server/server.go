@@ -1,7 +1,9 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -39,7 +41,7 @@ func NewServer(extensions map[string]app.Extension, addr string) *http.Server {
 		extension.Root = nil
 
 		for name, command := range extension.Commands {
-			command.Exec = ""
+			command.Exec = app.CommandExec{}
 			extension.Commands[name] = command
 		}
 
@@ -77,6 +79,23 @@ func NewServer(extensions map[string]app.Extension, addr string) *http.Server {
 			return
 		}
 
+		params, err := command.ValidateParams(input.With)
+		if err != nil {
+			var validationErrors app.ValidationErrors
+			if errors.As(err, &validationErrors) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]any{"errors": validationErrors})
+				return
+			}
+
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(fmt.Sprintf("Invalid params: %s", err)))
+			return
+		}
+		// The values are converted to the types of the params
+		input.With = params
+
 		cmd, err := command.Cmd(input, extension.Root.Path)
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
@@ -84,7 +103,16 @@ func NewServer(extensions map[string]app.Extension, addr string) *http.Server {
 			return
 		}
 
-		output, err := cmd.Output()
+		// The command is killed if the client disconnects or the command times out
+		ctx, cancel := command.WithTimeout(r.Context())
+		defer cancel()
+
+		output, err := app.Output(ctx, cmd)
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			w.WriteHeader(http.StatusGatewayTimeout)
+			w.Write([]byte(fmt.Sprintf("Command timed out after %s", command.Timeout)))
+			return
+		}
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
 			w.Write([]byte(fmt.Sprintf("Error running command: %s", err)))
@@ -30,24 +30,60 @@ func NewCopyTextCmd(text string) tea.Cmd {
 	return func() tea.Msg {
 		return CopyTextMsg{
 			Text: text,
+			Exit: true,
 		}
 	}
 }
 
+// CopyTextMsg copies the text to the clipboard.
+// Sunbeam exits once the text is copied if Exit is set, otherwise a toast is shown.
 type CopyTextMsg struct {
 	Text string
+	Exit bool
 }
 
 func NewOpenUrlCmd(Url string) tea.Cmd {
 	return func() tea.Msg {
 		return OpenUrlMsg{
-			Url: Url,
+			Url:  Url,
+			Exit: true,
 		}
 	}
 }
 
 type OpenUrlMsg struct {
-	Url string
+	Url  string
+	Exit bool
+}
+
+func NewOpenPathCmd(path string) tea.Cmd {
+	return func() tea.Msg {
+		return OpenPathMsg{
+			Path: path,
+			Exit: true,
+		}
+	}
+}
+
+// OpenPathMsg opens a file or a directory with the system opener, or with the open command of the user config.
+type OpenPathMsg struct {
+	Path string
+	Exit bool
+}
+
+func NewEditCmd(path string, line int) tea.Cmd {
+	return func() tea.Msg {
+		return EditMsg{
+			Path: path,
+			Line: line,
+		}
+	}
+}
+
+// EditMsg opens a file in the editor of the user, the line is ignored if it is zero.
+type EditMsg struct {
+	Path string
+	Line int
 }
 
 func NewReloadPageCmd(with map[string]app.CommandInput) tea.Cmd {
@@ -75,10 +111,18 @@ type PushPageMsg struct {
 	Page Page
 }
 
+// SendRequestMsg sends the request of an http action.
+type SendRequestMsg struct {
+	Action app.Action
+}
+
 type RunCommandMsg struct {
 	Command   string
 	With      map[string]app.CommandInput
 	OnSuccess string
+	Batch     bool
+	// Exit overrides the exit option of the command if it is set
+	Exit *bool
 }
 
 func NewAction(scriptAction app.Action) Action {
@@ -88,7 +132,12 @@ func NewAction(scriptAction app.Action) Action {
 		if scriptAction.Title == "" {
 			scriptAction.Title = "Copy to Clipboard"
 		}
-		cmd = NewCopyTextCmd(scriptAction.Text)
+		cmd = func() tea.Msg {
+			return CopyTextMsg{
+				Text: scriptAction.Text,
+				Exit: scriptAction.ShouldExit(),
+			}
+		}
 	case "reload-page":
 		if scriptAction.Title == "" {
 			scriptAction.Title = "Reload Page"
@@ -100,18 +149,61 @@ func NewAction(scriptAction app.Action) Action {
 				Command:   scriptAction.Command,
 				With:      scriptAction.With,
 				OnSuccess: scriptAction.OnSuccess,
+				Batch:     scriptAction.Batch,
+				Exit:      scriptAction.Exit,
+			}
+		}
+	case "http":
+		if scriptAction.Title == "" {
+			scriptAction.Title = "Send Request"
+		}
+		cmd = func() tea.Msg {
+			return SendRequestMsg{
+				Action: scriptAction,
 			}
 		}
 	case "open-url":
 		if scriptAction.Title == "" {
 			scriptAction.Title = "Open in Browser"
 		}
-		cmd = NewOpenUrlCmd(scriptAction.Url)
+		cmd = func() tea.Msg {
+			return OpenUrlMsg{
+				Url:  scriptAction.Url,
+				Exit: scriptAction.ShouldExit(),
+			}
+		}
+	case "open-path":
+		if scriptAction.Title == "" {
+			scriptAction.Title = "Open"
+		}
+		cmd = func() tea.Msg {
+			return OpenPathMsg{
+				Path: scriptAction.Path,
+				Exit: scriptAction.ShouldExit(),
+			}
+		}
+	case "edit":
+		if scriptAction.Title == "" {
+			scriptAction.Title = "Edit"
+		}
+		cmd = NewEditCmd(scriptAction.Path, scriptAction.Line)
 	default:
 		scriptAction.Title = "Unknown"
 		cmd = NewErrorCmd(fmt.Errorf("unknown action type: %s", scriptAction.Type))
 	}
 
+	if scriptAction.Confirm.Enabled {
+		title := scriptAction.Confirm.Title
+		if title == "" {
+			title = fmt.Sprintf("%s?", scriptAction.Title)
+		}
+		message := scriptAction.Confirm.Message
+		if message == "" {
+			message = "Are you sure you want to continue?"
+		}
+		cmd = NewConfirmCmd(title, message, cmd)
+	}
+
 	return Action{
 		Cmd:      cmd,
 		Title:    scriptAction.Title,
@@ -203,11 +295,15 @@ func (al ActionList) Update(msg tea.Msg) (ActionList, tea.Cmd) {
 				return al, nil
 			}
 			listItem, _ := selectedItem.(ListItem)
+
+			// The actions are hidden, since the page is still displayed if the action does not exit
+			al.Blur()
 			return al, listItem.Actions[0].Cmd
 		}
 
 		for _, action := range al.actions {
 			if key.Matches(msg, action.Binding()) {
+				al.Blur()
 				return al, action.Cmd
 			}
 		}
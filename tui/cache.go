@@ -0,0 +1,85 @@
+package tui
+
+import (
+	"container/list"
+	"strings"
+	"time"
+)
+
+// previewCache is shared by every list, so that previews are not run again when a page is reopened
+var previewCache = NewPreviewCache(100)
+
+// PreviewCache is a least recently used cache of preview outputs.
+// It is only accessed from the update loop, so it is not safe for concurrent use.
+type PreviewCache struct {
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List
+}
+
+type previewCacheEntry struct {
+	key       string
+	content   string
+	expiresAt time.Time
+}
+
+func NewPreviewCache(capacity int) *PreviewCache {
+	return &PreviewCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Get returns the cached content of a preview, if it has not expired.
+func (c *PreviewCache) Get(key string) (string, bool) {
+	element, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+
+	entry := element.Value.(previewCacheEntry)
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return "", false
+	}
+
+	c.order.MoveToFront(element)
+	return entry.content, true
+}
+
+// Set caches the content of a preview, the least recently used entry is evicted if the cache is full.
+// A zero ttl means that the content never expires.
+func (c *PreviewCache) Set(key string, content string, ttl time.Duration) {
+	entry := previewCacheEntry{
+		key:     key,
+		content: content,
+	}
+	if ttl > 0 {
+		entry.expiresAt = time.Now().Add(ttl)
+	}
+
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(entry)
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(previewCacheEntry).key)
+	}
+}
+
+// DeletePrefix removes the entries whose key starts with the prefix, for example the previews of a reloaded page.
+func (c *PreviewCache) DeletePrefix(prefix string) {
+	for key, element := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			c.order.Remove(element)
+			delete(c.entries, key)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package tui
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPreviewCache(t *testing.T) {
+	t.Run("least recently used entry is evicted", func(t *testing.T) {
+		cache := NewPreviewCache(2)
+		cache.Set("a", "A", 0)
+		cache.Set("b", "B", 0)
+		cache.Get("a")
+		cache.Set("c", "C", 0)
+
+		if _, ok := cache.Get("b"); ok {
+			t.Errorf("b should have been evicted")
+		}
+		if content, ok := cache.Get("a"); !ok || content != "A" {
+			t.Errorf("got %q, want %q", content, "A")
+		}
+		if content, ok := cache.Get("c"); !ok || content != "C" {
+			t.Errorf("got %q, want %q", content, "C")
+		}
+	})
+
+	t.Run("expired entry is not returned", func(t *testing.T) {
+		cache := NewPreviewCache(2)
+		cache.Set("a", "A", time.Millisecond)
+		time.Sleep(5 * time.Millisecond)
+
+		if _, ok := cache.Get("a"); ok {
+			t.Errorf("a should have expired")
+		}
+	})
+}
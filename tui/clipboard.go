@@ -0,0 +1,152 @@
+package tui
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52"
+	"github.com/pomdtr/sunbeam/utils"
+)
+
+// ClipboardBackends are the ways the text of copy actions can be copied
+var ClipboardBackends = []string{"native", "osc52", "command", "file"}
+
+// ClipboardConfig selects the clipboard of copy actions, the backend is detected from the environment if it is not set.
+type ClipboardConfig struct {
+	Backend string `yaml:"backend"`
+	// Copy is a command receiving the text on stdin, like wl-copy or pbcopy
+	Copy string `yaml:"copy"`
+	// Path is a file which is overwritten with the copied text
+	Path string `yaml:"path"`
+}
+
+type Clipboard interface {
+	Copy(text string) error
+}
+
+// systemClipboard is used by copy actions, it is replaced by the backend of the user config
+var systemClipboard Clipboard = NativeClipboard{}
+
+// SetClipboardConfig sets the clipboard used by copy actions.
+func SetClipboardConfig(config ClipboardConfig) error {
+	c, err := NewClipboard(config)
+	if err != nil {
+		return err
+	}
+
+	systemClipboard = c
+	return nil
+}
+
+// NewClipboard returns the clipboard selected by the config.
+func NewClipboard(config ClipboardConfig) (Clipboard, error) {
+	backend := config.Backend
+	if backend == "" {
+		backend = detectClipboardBackend(config)
+	}
+
+	switch backend {
+	case "native":
+		return NativeClipboard{}, nil
+	case "osc52":
+		return OSC52Clipboard{Env: os.Environ()}, nil
+	case "command":
+		if config.Copy == "" {
+			return nil, fmt.Errorf("the command clipboard requires a copy command")
+		}
+		return CommandClipboard{Command: config.Copy}, nil
+	case "file":
+		if config.Path == "" {
+			return nil, fmt.Errorf("the file clipboard requires a path")
+		}
+		path, err := utils.ResolvePath(config.Path)
+		if err != nil {
+			return nil, err
+		}
+		return FileClipboard{Path: path}, nil
+	default:
+		return nil, fmt.Errorf("invalid clipboard backend: %s, expected one of %s", backend, strings.Join(ClipboardBackends, ", "))
+	}
+}
+
+// detectClipboardBackend uses the terminal clipboard over ssh, or when no native clipboard utility is installed, like in containers.
+func detectClipboardBackend(config ClipboardConfig) string {
+	switch {
+	case config.Copy != "":
+		return "command"
+	case config.Path != "":
+		return "file"
+	case os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != "":
+		return "osc52"
+	case clipboard.Unsupported:
+		return "osc52"
+	default:
+		return "native"
+	}
+}
+
+// NativeClipboard uses the clipboard utilities of the system, like pbcopy, xclip or wl-copy.
+type NativeClipboard struct{}
+
+func (NativeClipboard) Copy(text string) error {
+	return clipboard.WriteAll(text)
+}
+
+// OSC52Clipboard asks the terminal to copy the text, using the OSC 52 escape sequence.
+// It works over ssh, but the terminal must support it, and it can not report failures.
+type OSC52Clipboard struct {
+	// Output defaults to the terminal, the ui is rendered to stdout and the sequence could be written in the middle of a frame
+	Output io.Writer
+	// Env is used to wrap the sequence for tmux and screen
+	Env []string
+}
+
+func (c OSC52Clipboard) Copy(text string) error {
+	output := c.Output
+	if output == nil {
+		tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+		if err != nil {
+			return fmt.Errorf("failed to open the terminal: %s", err)
+		}
+		defer tty.Close()
+		output = tty
+	}
+
+	osc52.NewOutput(output, c.Env).Copy(text)
+	return nil
+}
+
+// CommandClipboard runs a command with the text on stdin.
+type CommandClipboard struct {
+	Command string
+}
+
+func (c CommandClipboard) Copy(text string) error {
+	var stderr bytes.Buffer
+	cmd := exec.Command("sh", "-c", c.Command)
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if stderr.Len() > 0 {
+			return fmt.Errorf("%s: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return err
+	}
+
+	return nil
+}
+
+// FileClipboard writes the text to a file, for example a file shared with the host of a container.
+type FileClipboard struct {
+	Path string
+}
+
+func (c FileClipboard) Copy(text string) error {
+	return os.WriteFile(c.Path, []byte(text), 0600)
+}
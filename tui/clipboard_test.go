@@ -0,0 +1,121 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestClipboard(t *testing.T) {
+	t.Run("osc52", func(t *testing.T) {
+		var output bytes.Buffer
+		clipboard := OSC52Clipboard{Output: &output, Env: []string{"TERM=xterm-256color"}}
+		if err := clipboard.Copy("hello"); err != nil {
+			t.Fatal(err)
+		}
+
+		want := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte("hello")) + "\x07"
+		if output.String() != want {
+			t.Errorf("got %q, want %q", output.String(), want)
+		}
+	})
+
+	t.Run("osc52 in tmux", func(t *testing.T) {
+		var output bytes.Buffer
+		clipboard := OSC52Clipboard{Output: &output, Env: []string{"TERM=screen", "TMUX=/tmp/tmux-1000/default"}}
+		if err := clipboard.Copy("hello"); err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.HasPrefix(output.String(), "\x1bPtmux;") {
+			t.Errorf("sequence is not wrapped for tmux: %q", output.String())
+		}
+	})
+
+	t.Run("command", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "clipboard")
+		clipboard := CommandClipboard{Command: "cat > " + path}
+		if err := clipboard.Copy("hello"); err != nil {
+			t.Fatal(err)
+		}
+
+		if content, _ := os.ReadFile(path); string(content) != "hello" {
+			t.Errorf("got %q, want %q", content, "hello")
+		}
+	})
+
+	t.Run("failing command", func(t *testing.T) {
+		clipboard := CommandClipboard{Command: "echo no display >&2; exit 1"}
+		err := clipboard.Copy("hello")
+		if err == nil || !strings.Contains(err.Error(), "no display") {
+			t.Errorf("got %v, want the stderr of the command", err)
+		}
+	})
+
+	t.Run("file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "clipboard")
+		clipboard := FileClipboard{Path: path}
+		for _, text := range []string{"first", "second"} {
+			if err := clipboard.Copy(text); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if content, _ := os.ReadFile(path); string(content) != "second" {
+			t.Errorf("got %q, want %q", content, "second")
+		}
+	})
+}
+
+func TestNewClipboard(t *testing.T) {
+	t.Setenv("SSH_TTY", "")
+	t.Setenv("SSH_CONNECTION", "")
+
+	type testCase struct {
+		config ClipboardConfig
+		env    map[string]string
+		want   Clipboard
+	}
+
+	cases := map[string]testCase{
+		"copy command":     {config: ClipboardConfig{Copy: "wl-copy"}, want: CommandClipboard{Command: "wl-copy"}},
+		"file":             {config: ClipboardConfig{Path: "/tmp/clipboard"}, want: FileClipboard{Path: "/tmp/clipboard"}},
+		"ssh":              {env: map[string]string{"SSH_TTY": "/dev/pts/0"}, want: OSC52Clipboard{}},
+		"backend override": {config: ClipboardConfig{Backend: "native"}, env: map[string]string{"SSH_TTY": "/dev/pts/0"}, want: NativeClipboard{}},
+	}
+
+	for key, c := range cases {
+		c := c
+		t.Run(key, func(t *testing.T) {
+			for name, value := range c.env {
+				t.Setenv(name, value)
+			}
+
+			got, err := NewClipboard(c.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// The output of the terminal clipboard is not compared
+			if _, ok := c.want.(OSC52Clipboard); ok {
+				if _, ok := got.(OSC52Clipboard); !ok {
+					t.Errorf("got %T, want %T", got, c.want)
+				}
+				return
+			}
+
+			if got != c.want {
+				t.Errorf("got %#v, want %#v", got, c.want)
+			}
+		})
+	}
+
+	for _, config := range []ClipboardConfig{{Backend: "command"}, {Backend: "file"}, {Backend: "unknown"}} {
+		if _, err := NewClipboard(config); err == nil {
+			t.Errorf("%+v should be rejected", config)
+		}
+	}
+}
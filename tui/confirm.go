@@ -0,0 +1,108 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Confirm is a modal page asking the user to confirm a dangerous action before running it.
+type Confirm struct {
+	title     string
+	message   string
+	onConfirm tea.Cmd
+
+	// The focus is on no by default, so that enter does not run the action by mistake
+	focusYes      bool
+	Confirmed     bool
+	width, height int
+}
+
+func NewConfirm(title string, message string, onConfirm tea.Cmd) *Confirm {
+	return &Confirm{
+		title:     title,
+		message:   message,
+		onConfirm: onConfirm,
+	}
+}
+
+// NewConfirmCmd pushes a confirmation page, the command is run once the user confirms.
+func NewConfirmCmd(title string, message string, cmd tea.Cmd) tea.Cmd {
+	return func() tea.Msg {
+		return PushPageMsg{
+			Page: NewConfirm(title, message, cmd),
+		}
+	}
+}
+
+func (c *Confirm) Init() tea.Cmd {
+	return nil
+}
+
+func (c *Confirm) SetSize(width, height int) {
+	c.width, c.height = width, height
+}
+
+func (c *Confirm) confirm() tea.Cmd {
+	c.Confirmed = true
+	if c.onConfirm == nil {
+		return PopCmd
+	}
+
+	// The page is popped first, so that the command is run by the page below
+	return tea.Sequence(PopCmd, c.onConfirm)
+}
+
+func (c *Confirm) Update(msg tea.Msg) (Page, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return c, nil
+	}
+
+	switch keyMsg.String() {
+	case "y", "Y":
+		return c, c.confirm()
+	case "n", "N", "esc", "q":
+		return c, PopCmd
+	case "left", "right", "tab", "shift+tab", "h", "l":
+		c.focusYes = !c.focusYes
+	case "enter":
+		if c.focusYes {
+			return c, c.confirm()
+		}
+		return c, PopCmd
+	}
+
+	return c, nil
+}
+
+func (c *Confirm) View() string {
+	button := lipgloss.NewStyle().Padding(0, 2)
+	focused := button.Copy().Bold(true).Reverse(true)
+
+	yes, no := button.Render("Yes"), focused.Render("No")
+	if c.focusYes {
+		yes, no = focused.Copy().Foreground(lipgloss.Color("1")).Render("Yes"), button.Render("No")
+	}
+
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("1")).Render(c.title)
+	buttons := lipgloss.JoinHorizontal(lipgloss.Top, yes, " ", no)
+	help := styles.Faint.Render("y/n · ←/→ · ↩")
+
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("1")).
+		Padding(1, 2).
+		Render(lipgloss.JoinVertical(lipgloss.Center, title, "", c.message, "", buttons, "", help))
+
+	return lipgloss.Place(c.width, c.height, lipgloss.Center, lipgloss.Center, box)
+}
+
+// AskConfirmation displays a confirmation page outside of the launcher, and reports whether the user confirmed.
+func AskConfirmation(title string, message string) (bool, error) {
+	confirm := NewConfirm(title, message, nil)
+	if err := Draw(NewModel(confirm), true); err != nil {
+		return false, err
+	}
+
+	return confirm.Confirmed, nil
+}
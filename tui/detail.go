@@ -1,10 +1,14 @@
 package tui
 
 import (
+	"strings"
+
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/pomdtr/sunbeam/app"
+	"github.com/pomdtr/sunbeam/utils"
 )
 
 type Detail struct {
@@ -14,6 +18,13 @@ type Detail struct {
 	actions        ActionList
 	PreviewCommand func() string
 	footer         Footer
+
+	Format   string
+	Language string
+	content  string
+
+	width, height int
+	Metadata      []app.MetadataItem
 }
 
 func NewDetail(title string) *Detail {
@@ -85,7 +96,7 @@ func (c Detail) Update(msg tea.Msg) (Page, tea.Cmd) {
 		}
 	case PreviewContentMsg:
 		c.SetIsLoading(false)
-		c.viewport.SetContent(string(msg))
+		c.SetContent(string(msg))
 	}
 	var cmds []tea.Cmd
 	var cmd tea.Cmd
@@ -107,10 +118,36 @@ func (c *Detail) SetSize(width, height int) {
 	c.header.Width = width
 	c.actions.SetSize(width, height)
 
+	c.width, c.height = width, height
 	viewportHeight := height - lipgloss.Height(c.header.View()) - lipgloss.Height(c.footer.View())
 
 	c.viewport.Width = width
 	c.viewport.Height = viewportHeight
+	if len(c.Metadata) > 0 {
+		if c.hasSidebar() {
+			c.viewport.Width = width - c.sidebarWidth() - 1
+		} else {
+			// The separator line takes one row
+			c.viewport.Height = utils.Max(0, viewportHeight-lipgloss.Height(RenderMetadataInline(c.Metadata, width))-1)
+		}
+	}
+
+	// The content is rendered again, since markdown is wrapped to the viewport width
+	c.SetContent(c.content)
+}
+
+// SetContent renders the content in the viewport, according to the format of the detail.
+func (c *Detail) SetContent(content string) {
+	c.content = content
+	c.viewport.SetContent(RenderPreview(content, c.Format, c.Language, c.viewport.Width-c.viewport.Style.GetHorizontalFrameSize()))
+}
+
+func (c *Detail) hasSidebar() bool {
+	return c.width >= metadataSidebarMinWidth
+}
+
+func (c *Detail) sidebarWidth() int {
+	return c.width / 3
 }
 
 func (c *Detail) View() string {
@@ -118,5 +155,19 @@ func (c *Detail) View() string {
 		return c.actions.View()
 	}
 
-	return lipgloss.JoinVertical(lipgloss.Left, c.header.View(), c.viewport.View(), c.footer.View())
+	if len(c.Metadata) == 0 {
+		return lipgloss.JoinVertical(lipgloss.Left, c.header.View(), c.viewport.View(), c.footer.View())
+	}
+
+	var body string
+	if c.hasSidebar() {
+		separator := strings.TrimSuffix(strings.Repeat("│\n", c.viewport.Height), "\n")
+		sidebar := lipgloss.NewStyle().Width(c.sidebarWidth()).MaxHeight(c.viewport.Height).Render(RenderMetadataSidebar(c.Metadata, c.sidebarWidth()-2))
+		body = lipgloss.JoinHorizontal(lipgloss.Top, c.viewport.View(), separator, sidebar)
+	} else {
+		separator := styles.Faint.Render(strings.Repeat("─", c.width))
+		body = lipgloss.JoinVertical(lipgloss.Left, c.viewport.View(), separator, RenderMetadataInline(c.Metadata, c.width-2))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, c.header.View(), body, c.footer.View())
 }
@@ -6,8 +6,6 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-	"github.com/pomdtr/sunbeam/utils"
-	"github.com/sahilm/fuzzy"
 )
 
 type FilterItem interface {
@@ -16,12 +14,27 @@ type FilterItem interface {
 	ID() string
 }
 
+// HighlightedItem is implemented by items which highlight the runes matching the query.
+// The matched indexes are byte offsets in the filter value of the item.
+type HighlightedItem interface {
+	Highlight(matchedIndexes []int) FilterItem
+}
+
+// SectionedItem is implemented by items belonging to a section, whose title is rendered above the section items.
+type SectionedItem interface {
+	SectionTitle() string
+}
+
 type Filter struct {
 	minIndex      int
 	Width, Height int
 	Query         string
 	Background    lipgloss.TerminalColor
 	Less          func(i, j FilterItem) bool
+	// UngroupedSearch ranks matches regardless of their section when a query is typed
+	UngroupedSearch bool
+	// MatchMode is one of MatchModes, fuzzy is used if it is empty
+	MatchMode string
 
 	emptyText string
 	items     []FilterItem
@@ -63,10 +76,14 @@ func (f *Filter) FilterItems(query string) {
 	if query == "" {
 		filtered = f.items
 	} else {
-		matches := fuzzy.Find(query, values)
+		matches := MatchValues(f.MatchMode, query, values)
 		filtered = make([]FilterItem, len(matches))
 		for i, match := range matches {
-			filtered[i] = f.items[match.Index]
+			item := f.items[match.Index]
+			if highlighted, ok := item.(HighlightedItem); ok {
+				item = highlighted.Highlight(match.MatchedIndexes)
+			}
+			filtered[i] = item
 		}
 	}
 
@@ -76,6 +93,23 @@ func (f *Filter) FilterItems(query string) {
 		})
 	}
 
+	// Keep the matches of each section together, sections are sorted in the order of their first item
+	if query == "" || !f.UngroupedSearch {
+		sectionIndexes := make(map[string]int)
+		for _, item := range f.items {
+			section := itemSection(item)
+			if _, ok := sectionIndexes[section]; !ok {
+				sectionIndexes[section] = len(sectionIndexes)
+			}
+		}
+
+		// The filtered slice may be the items slice, which must not be reordered
+		filtered = append([]FilterItem(nil), filtered...)
+		sort.SliceStable(filtered, func(i, j int) bool {
+			return sectionIndexes[itemSection(filtered[i])] < sectionIndexes[itemSection(filtered[j])]
+		})
+	}
+
 	f.filtered = filtered
 
 	// Reset the cursor
@@ -83,6 +117,69 @@ func (f *Filter) FilterItems(query string) {
 	f.minIndex = 0
 }
 
+// Select moves the cursor to the item with the given id, starting the view at minIndex if the item is visible from there.
+func (f *Filter) Select(id string, minIndex int) {
+	for i, item := range f.filtered {
+		if item.ID() != id {
+			continue
+		}
+
+		f.cursor = i
+		f.minIndex = minIndex
+		if f.cursor < f.minIndex || !f.fits(f.minIndex, f.cursor) {
+			f.scrollUpTo(f.cursor)
+		}
+		return
+	}
+}
+
+func itemSection(item FilterItem) string {
+	if item, ok := item.(SectionedItem); ok {
+		return item.SectionTitle()
+	}
+	return ""
+}
+
+// hasHeader reports whether the section header is rendered above the item at index, when the view starts at minIndex.
+// The header of the first visible item is always rendered, to keep its section visible while scrolling.
+func (f Filter) hasHeader(index int, minIndex int) bool {
+	if f.UngroupedSearch && f.Query != "" {
+		return false
+	}
+
+	section := itemSection(f.filtered[index])
+	if section == "" {
+		return false
+	}
+	return index == minIndex || itemSection(f.filtered[index-1]) != section
+}
+
+// fits reports whether the items from minIndex to index are visible, including the section headers.
+func (f Filter) fits(minIndex int, index int) bool {
+	rows := 0
+	for i := minIndex; i <= index; i++ {
+		if f.hasHeader(i, minIndex) {
+			rows++
+		}
+		rows += f.itemHeight()
+	}
+
+	// The separator of the last item can be cut
+	if f.DrawLines {
+		rows--
+	}
+
+	return rows <= f.Height
+}
+
+// scrollUpTo shows as many items as possible above the item at index, keeping it visible.
+func (f *Filter) scrollUpTo(index int) {
+	f.minIndex = index
+	for f.minIndex > 0 && f.fits(f.minIndex-1, index) {
+		f.minIndex--
+	}
+}
+
 func (m Filter) Init() tea.Cmd { return nil }
 
 func (m Filter) View() string {
@@ -91,6 +188,15 @@ func (m Filter) View() string {
 	index := m.minIndex
 	availableHeight := m.Height
 	for availableHeight > 0 && index < len(m.filtered) {
+		if m.hasHeader(index, m.minIndex) {
+			header := lipgloss.NewStyle().Bold(true).Faint(true).MaxWidth(itemWidth).Render(itemSection(m.filtered[index]))
+			rows = append(rows, header)
+			availableHeight--
+			if availableHeight == 0 {
+				break
+			}
+		}
+
 		item := m.filtered[index]
 		itemView := item.Render(itemWidth, index == m.cursor)
 		rows = append(rows, itemView)
@@ -152,18 +258,14 @@ func (m *Filter) CursorUp() {
 		}
 	} else {
 		m.cursor = len(m.filtered) - 1
-		m.minIndex = utils.Max(0, m.cursor-m.nbVisibleItems()+1)
+		m.scrollUpTo(m.cursor)
 	}
 }
 
-func (m Filter) nbVisibleItems() int {
-	return m.Height/m.itemHeight() + m.Height%m.itemHeight()
-}
-
 func (m *Filter) CursorDown() {
 	if m.cursor < len(m.filtered)-1 {
 		m.cursor += 1
-		if m.cursor >= m.minIndex+m.nbVisibleItems() {
+		for m.minIndex < m.cursor && !m.fits(m.minIndex, m.cursor) {
 			m.minIndex += 1
 		}
 	} else {
@@ -0,0 +1,76 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilterSections(t *testing.T) {
+	items := []FilterItem{
+		ListItem{Id: "1", Title: "Clone Repository", Section: "Git"},
+		ListItem{Id: "2", Title: "List Pull Requests", Section: "GitHub"},
+		ListItem{Id: "3", Title: "Commit Changes", Section: "Git"},
+		ListItem{Id: "4", Title: "List Repositories", Section: "GitHub"},
+		ListItem{Id: "5", Title: "Empty Section Item", Section: "Other"},
+	}
+
+	filter := NewFilter()
+	filter.SetSize(40, 20)
+	filter.SetItems(items)
+
+	filteredIds := func() []string {
+		ids := make([]string, len(filter.filtered))
+		for i, item := range filter.filtered {
+			ids[i] = item.ID()
+		}
+		return ids
+	}
+
+	t.Run("items are grouped by section", func(t *testing.T) {
+		filter.FilterItems("")
+		want := []string{"1", "3", "2", "4", "5"}
+		if got := filteredIds(); !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("matches are grouped by section", func(t *testing.T) {
+		filter.FilterItems("e")
+		if len(filter.filtered) < 2 {
+			t.Fatalf("expected several matches, got %v", filteredIds())
+		}
+
+		seen := make(map[string]bool)
+		previous := ""
+		for _, item := range filter.filtered {
+			section := itemSection(item)
+			if section != previous && seen[section] {
+				t.Fatalf("matches of section %s are not grouped: %v", section, filteredIds())
+			}
+			seen[section] = true
+			previous = section
+		}
+	})
+
+	t.Run("cursor skips section headers", func(t *testing.T) {
+		filter.FilterItems("")
+		filter.CursorDown()
+		filter.CursorDown()
+		if got := filter.Selection().ID(); got != "2" {
+			t.Errorf("got selection %s, want 2", got)
+		}
+	})
+
+	t.Run("selection stays visible", func(t *testing.T) {
+		filter.SetSize(40, 3)
+		filter.FilterItems("")
+		for i := 0; i < 3; i++ {
+			filter.CursorDown()
+		}
+
+		// The header of the section and the selected item must fit in the view
+		if !filter.fits(filter.minIndex, filter.cursor) {
+			t.Errorf("selected item %d is not visible from index %d", filter.cursor, filter.minIndex)
+		}
+	})
+}
@@ -11,6 +11,7 @@ import (
 
 type Footer struct {
 	title    string
+	status   string
 	Width    int
 	bindings []key.Binding
 }
@@ -25,11 +26,20 @@ func (f *Footer) SetBindings(bindings ...key.Binding) {
 	f.bindings = bindings
 }
 
+// SetStatus sets a text displayed next to the title, like the number of selected items
+func (f *Footer) SetStatus(status string) {
+	f.status = status
+}
+
 func (f Footer) View() string {
 	horizontal := strings.Repeat("─", f.Width)
+	title := f.title
+	if f.status != "" {
+		title = fmt.Sprintf("%s · %s", f.title, f.status)
+	}
 
 	if len(f.bindings) == 0 {
-		title := styles.Italic.Copy().Padding(0, 1).Width(f.Width).Render(f.title)
+		title := styles.Italic.Copy().Padding(0, 1).Width(f.Width).Render(title)
 		return lipgloss.JoinVertical(lipgloss.Left, horizontal, title)
 	}
 
@@ -41,7 +51,7 @@ func (f Footer) View() string {
 	help = fmt.Sprintf("  %s ", help)
 
 	availableWidth := utils.Max(0, f.Width-lipgloss.Width(help))
-	title := fmt.Sprintf(" %s", f.title)
+	title = fmt.Sprintf(" %s", title)
 
 	if availableWidth < lipgloss.Width(title) {
 		title = title[:availableWidth]
@@ -1,6 +1,8 @@
 package tui
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
@@ -9,6 +11,7 @@ import (
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/reflow/truncate"
 	"github.com/pomdtr/sunbeam/app"
 	"github.com/pomdtr/sunbeam/utils"
 )
@@ -16,13 +19,24 @@ import (
 const debounceDuration = 300 * time.Millisecond
 
 type ListItem struct {
-	Id          string
-	Title       string
-	Subtitle    string
-	Preview     string
-	PreviewCmd  func() string
-	Accessories []string
-	Actions     []Action
+	Id         string
+	Title      string
+	Subtitle   string
+	Preview    string
+	PreviewCmd func(ctx context.Context) (string, error)
+	// PreviewTTL is the duration for which the output of PreviewCmd is cached, zero means until sunbeam exits
+	PreviewTTL time.Duration
+	// PreviewFormat and PreviewLanguage describe how the preview is rendered, see RenderPreview
+	PreviewFormat   string
+	PreviewLanguage string
+	Accessories     []string
+	Actions         []Action
+	Section         string
+	// Payload is passed to batch actions instead of the id, see List.BatchInput
+	Payload any
+	Checked bool
+	// MatchedIndexes are the byte offsets of the runes matching the query in the filter value
+	MatchedIndexes []int
 }
 
 func ParseScriptItem(scriptItem app.ListItem) ListItem {
@@ -35,12 +49,15 @@ func ParseScriptItem(scriptItem app.ListItem) ListItem {
 	}
 
 	return ListItem{
-		Id:          scriptItem.Id,
-		Title:       scriptItem.Title,
-		Subtitle:    scriptItem.Subtitle,
-		Preview:     scriptItem.Preview.Text,
-		Accessories: scriptItem.Accessories,
-		Actions:     actions,
+		Id:              scriptItem.Id,
+		Title:           scriptItem.Title,
+		Subtitle:        scriptItem.Subtitle,
+		Preview:         scriptItem.Preview.Text,
+		PreviewFormat:   scriptItem.Preview.Format,
+		PreviewLanguage: scriptItem.Preview.Language,
+		Accessories:     scriptItem.Accessories,
+		Actions:         actions,
+		Payload:         scriptItem.Payload,
 	}
 
 }
@@ -49,6 +66,10 @@ func (i ListItem) ID() string {
 	return i.Id
 }
 
+func (i ListItem) SectionTitle() string {
+	return i.Section
+}
+
 func (i ListItem) FilterValue() string {
 	if i.Subtitle == "" {
 		return i.Title
@@ -56,20 +77,28 @@ func (i ListItem) FilterValue() string {
 	return fmt.Sprintf("%s %s", i.Title, i.Subtitle)
 }
 
+// Highlight returns a copy of the item, with the runes matching the query highlighted.
+func (i ListItem) Highlight(matchedIndexes []int) FilterItem {
+	i.MatchedIndexes = matchedIndexes
+	return i
+}
+
 func (i ListItem) Render(width int, selected bool) string {
 	if width == 0 {
 		return ""
 	}
 
-	var title string
+	prefix := "  "
 	titleStyle := lipgloss.NewStyle().Bold(true)
 	if selected {
-		title = fmt.Sprintf("> %s", i.Title)
+		prefix = "> "
 		titleStyle = titleStyle.Foreground(lipgloss.Color("13"))
-	} else {
-		title = fmt.Sprintf("  %s", i.Title)
+	}
+	if i.Checked {
+		prefix = fmt.Sprintf("%s✓ ", prefix)
 	}
 
+	title := fmt.Sprintf("%s%s", prefix, i.Title)
 	subtitle := fmt.Sprintf(" %s", i.Subtitle)
 	var blanks string
 	accessories := fmt.Sprintf(" %s", strings.Join(i.Accessories, " · "))
@@ -79,22 +108,62 @@ func (i ListItem) Render(width int, selected bool) string {
 		availableWidth := width - lipgloss.Width(title+subtitle+accessories)
 		blanks = strings.Repeat(" ", availableWidth)
 	} else if width >= lipgloss.Width(title+accessories) {
-		subtitle = subtitle[:width-lipgloss.Width(title+accessories)]
+		subtitle = truncate.String(subtitle, uint(width-lipgloss.Width(title+accessories)))
 	} else if width >= lipgloss.Width(title) {
 		subtitle = ""
-		accessories = accessories[:width-lipgloss.Width(title)]
+		accessories = truncate.String(accessories, uint(width-lipgloss.Width(title)))
 	} else {
+		subtitle = ""
 		accessories = ""
-		title = title[:utils.Min(len(title), width)]
+		title = truncate.String(title, uint(width))
 	}
 
-	title = titleStyle.Render(title)
-	subtitle = styles.Faint.Render(subtitle)
+	// The matched indexes are offsets in the filter value, which is the title followed by the subtitle
+	title = highlightMatches(title, -len(prefix), i.MatchedIndexes, titleStyle)
+	subtitle = highlightMatches(subtitle, len(i.Title), i.MatchedIndexes, styles.Faint)
 	accessories = styles.Faint.Render(accessories)
 
 	return lipgloss.JoinHorizontal(lipgloss.Top, title, subtitle, blanks, accessories)
 }
 
+// highlightMatches renders text with the style, and its runes whose offset plus the given offset is matched with the match style.
+func highlightMatches(text string, offset int, matchedIndexes []int, style lipgloss.Style) string {
+	if len(matchedIndexes) == 0 {
+		return style.Render(text)
+	}
+
+	matched := make(map[int]bool, len(matchedIndexes))
+	for _, index := range matchedIndexes {
+		matched[index] = true
+	}
+
+	var builder strings.Builder
+	var segment strings.Builder
+	segmentMatched := false
+	flush := func() {
+		if segment.Len() == 0 {
+			return
+		}
+		if segmentMatched {
+			builder.WriteString(styles.Match.Render(segment.String()))
+		} else {
+			builder.WriteString(style.Render(segment.String()))
+		}
+		segment.Reset()
+	}
+
+	for index, r := range text {
+		if isMatched := matched[index+offset]; isMatched != segmentMatched {
+			flush()
+			segmentMatched = isMatched
+		}
+		segment.WriteRune(r)
+	}
+	flush()
+
+	return builder.String()
+}
+
 type List struct {
 	header  Header
 	footer  Footer
@@ -102,9 +171,27 @@ type List struct {
 
 	IsGenerator bool
 	ShowPreview bool
+	// PreviewLayout is resolved from the user config and the page, see SetPreviewLayout
+	PreviewLayout app.PreviewLayout
+	previewHidden bool
+	width, height int
+	// MultiSelect lists toggle the selection of items with ctrl+space, or space while the query is empty
+	MultiSelect bool
+	checked     map[string]bool
+
+	// ctx is the parent of preview commands, it is done when the page is closed
+	ctx context.Context
+	// cacheKey identifies the page in the preview cache
+	cacheKey      string
+	previewCtx    context.Context
+	cancelPreview context.CancelFunc
+	// isLoading is set while the command of the page runs, the spinner is also displayed while a preview loads
+	isLoading bool
 
 	filter   Filter
 	viewport viewport.Model
+
+	previewContent string
 }
 
 func NewList(title string) *List {
@@ -116,6 +203,7 @@ func NewList(title string) *List {
 	viewport.Style = lipgloss.NewStyle().Padding(0, 1)
 	filter := NewFilter()
 	filter.DrawLines = true
+	filter.MatchMode = defaultMatchMode
 	footer := NewFooter(title)
 
 	return &List{
@@ -124,6 +212,10 @@ func NewList(title string) *List {
 		filter:   filter,
 		viewport: viewport,
 		footer:   footer,
+		checked:  make(map[string]bool),
+		ctx:      context.Background(),
+
+		PreviewLayout: previewConfig.PreviewLayout,
 	}
 }
 
@@ -136,19 +228,52 @@ func (c *List) Init() tea.Cmd {
 	return c.header.Focus()
 }
 
+// SetPreviewLayout overrides the layout of the user config with the one of the page.
+func (c *List) SetPreviewLayout(layout app.PreviewLayout) error {
+	layout, err := mergePreviewLayout(c.PreviewLayout, layout)
+	if err != nil {
+		return err
+	}
+
+	c.PreviewLayout = layout
+	c.SetSize(c.width, c.height)
+	return nil
+}
+
+// previewVisible reports whether the preview is displayed, it can be hidden by the user or because the terminal is too narrow.
+func (c List) previewVisible() bool {
+	if !c.ShowPreview || c.previewHidden {
+		return false
+	}
+
+	return c.PreviewLayout.Position == "bottom" || c.width >= c.PreviewLayout.MinWidth
+}
+
 func (c *List) SetSize(width, height int) {
+	c.width, c.height = width, height
 	availableHeight := utils.Max(0, height-lipgloss.Height(c.header.View())-lipgloss.Height(c.footer.View()))
 	c.footer.Width = width
 	c.header.Width = width
 	c.actions.SetSize(width, height)
-	if c.ShowPreview {
-		listWidth := width / 3
-		c.filter.SetSize(listWidth, availableHeight)
-		c.viewport.Width = width - listWidth
-		c.viewport.Height = availableHeight
-	} else {
+
+	if !c.previewVisible() {
 		c.filter.SetSize(width, availableHeight)
+	} else if c.PreviewLayout.Position == "bottom" {
+		// The separator takes one row
+		previewHeight := int(float64(availableHeight) * c.PreviewLayout.Ratio)
+		c.filter.SetSize(width, utils.Max(0, availableHeight-previewHeight-1))
+		c.viewport.Width = width
+		c.viewport.Height = previewHeight
+	} else {
+		// The separator takes one column
+		previewWidth := int(float64(width) * c.PreviewLayout.Ratio)
+		c.filter.SetSize(utils.Max(0, width-previewWidth-1), availableHeight)
+		c.viewport.Width = previewWidth
+		c.viewport.Height = availableHeight
 	}
+
+	// The preview is rendered again, since markdown is wrapped to the viewport width
+	c.setPreview(c.previewContent)
 }
 
 func (c List) Selection() *ListItem {
@@ -163,28 +288,222 @@ func (c List) Selection() *ListItem {
 func (c *List) SetItems(items []ListItem) tea.Cmd {
 	filterItems := make([]FilterItem, len(items))
 	for i, item := range items {
+		item.Checked = c.checked[item.Id]
 		filterItems[i] = item
 	}
 
+	var selectionId string
+	if selection := c.filter.Selection(); selection != nil {
+		selectionId = selection.ID()
+	}
+	minIndex := c.filter.minIndex
+
 	c.filter.SetItems(filterItems)
-	c.filter.FilterItems(c.Query())
-	if c.Selection() != nil {
-		c.updateSelection(c.filter)
+	c.filter.FilterItems(c.filterQuery(c.Query()))
+	c.updateStatus()
+
+	// Keep the selection and the scroll position when the items are updated, for example while they are streamed
+	if selectionId != "" {
+		c.filter.Select(selectionId, minIndex)
+	}
+
+	selection := c.filter.Selection()
+	if selection == nil {
+		return nil
+	}
+
+	c.updateSelection(c.filter)
+	if selection.ID() == selectionId {
+		return nil
+	}
+
+	return func() tea.Msg {
+		return SelectionChangeMsg{SelectionId: selection.ID()}
 	}
-	return nil
 }
 
 func (c *List) SetIsLoading(isLoading bool) tea.Cmd {
-	return c.header.SetIsLoading(isLoading)
+	c.isLoading = isLoading
+	return c.header.SetIsLoading(isLoading || c.previewCtx != nil)
+}
+
+// ToggleSelection checks or unchecks the selected item, then moves the cursor to the next one.
+func (c *List) ToggleSelection() {
+	selection := c.filter.Selection()
+	if selection == nil {
+		return
+	}
+
+	id := selection.ID()
+	if c.checked[id] {
+		delete(c.checked, id)
+	} else {
+		c.checked[id] = true
+	}
+
+	// The filtered items can share their backing array with the items, so the flag is set in both
+	for _, items := range [][]FilterItem{c.filter.items, c.filter.filtered} {
+		for i, item := range items {
+			if listItem, ok := item.(ListItem); ok && listItem.Id == id {
+				listItem.Checked = c.checked[id]
+				items[i] = listItem
+			}
+		}
+	}
+
+	c.filter.CursorDown()
+	c.updateStatus()
+}
+
+// CheckedItems returns the checked items, in the order of the list.
+func (c List) CheckedItems() []ListItem {
+	items := make([]ListItem, 0)
+	for _, item := range c.filter.items {
+		if listItem, ok := item.(ListItem); ok && listItem.Checked {
+			items = append(items, listItem)
+		}
+	}
+	return items
+}
+
+// ClearChecked unchecks all the items of the list.
+func (c *List) ClearChecked() {
+	c.checked = make(map[string]bool)
+	items := make([]ListItem, 0, len(c.filter.items))
+	for _, item := range c.filter.items {
+		items = append(items, item.(ListItem))
+	}
+	c.SetItems(items)
+}
+
+// BatchInput returns the payloads of the checked items as a json array, or the one of the selected item if none are checked.
+// Items without a payload are represented by their id.
+func (c List) BatchInput() (string, error) {
+	items := c.CheckedItems()
+	if len(items) == 0 {
+		if selection := c.Selection(); selection != nil {
+			items = append(items, *selection)
+		}
+	}
+
+	payloads := make([]any, len(items))
+	for i, item := range items {
+		if item.Payload != nil {
+			payloads[i] = item.Payload
+		} else {
+			payloads[i] = item.Id
+		}
+	}
+
+	input, err := json.Marshal(payloads)
+	if err != nil {
+		return "", err
+	}
+
+	// Line based tools like read ignore the last line if it is not terminated
+	return string(input) + "\n", nil
+}
+
+// isToggleKey reports whether the key toggles the selection of a multi-select list.
+// Space is only used while the query is empty, so that queries can contain spaces.
+func (c List) isToggleKey(msg tea.KeyMsg) bool {
+	if !c.MultiSelect || c.actions.Focused() {
+		return false
+	}
+
+	switch msg.String() {
+	// Terminals send ctrl+space as ctrl+@
+	case "ctrl+@":
+		return true
+	case " ":
+		return c.Query() == ""
+	default:
+		return false
+	}
+}
+
+func (c *List) updateStatus() {
+	if !c.MultiSelect {
+		return
+	}
+
+	if count := len(c.CheckedItems()); count > 0 {
+		c.footer.SetStatus(fmt.Sprintf("%d selected", count))
+	} else {
+		c.footer.SetStatus("")
+	}
 }
 
 type PreviewContentMsg string
 
+// ListPreviewMsg holds the output of the preview command of a list item.
+type ListPreviewMsg struct {
+	ItemId  string
+	Content string
+	Err     error
+	ttl     time.Duration
+	run     context.Context
+}
+
+func (c List) previewKey(itemId string) string {
+	// The items of generators depend on the query, their ids can be reused across queries
+	if c.IsGenerator {
+		return fmt.Sprintf("%s/%s/%s", c.cacheKey, c.Query(), itemId)
+	}
+	return fmt.Sprintf("%s/%s", c.cacheKey, itemId)
+}
+
+// stopPreview cancels the running preview command, its output would be displayed for the wrong item otherwise.
+func (c *List) stopPreview() {
+	if c.cancelPreview == nil {
+		return
+	}
+
+	c.cancelPreview()
+	c.previewCtx, c.cancelPreview = nil, nil
+	c.header.SetIsLoading(c.isLoading)
+}
+
+// loadPreview displays the cached preview of the item, or runs its preview command.
+func (c *List) loadPreview(item ListItem) tea.Cmd {
+	c.stopPreview()
+
+	key := c.previewKey(item.Id)
+	if content, ok := previewCache.Get(key); ok {
+		c.viewport.SetYOffset(0)
+		c.setPreview(content)
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(c.ctx)
+	c.previewCtx, c.cancelPreview = ctx, cancel
+
+	return tea.Sequence(c.header.SetIsLoading(true), func() tea.Msg {
+		content, err := item.PreviewCmd(ctx)
+		return ListPreviewMsg{ItemId: item.Id, Content: content, Err: err, ttl: item.PreviewTTL, run: ctx}
+	})
+}
+
+// setPreview renders the preview of the selected item in the viewport.
+func (l *List) setPreview(content string) {
+	l.previewContent = content
+	if !l.previewVisible() {
+		return
+	}
+
+	var format, language string
+	if selection := l.Selection(); selection != nil {
+		format, language = selection.PreviewFormat, selection.PreviewLanguage
+	}
+
+	l.viewport.SetContent(RenderPreview(content, format, language, l.viewport.Width-l.viewport.Style.GetHorizontalFrameSize()))
+}
+
 func (l *List) updateSelection(filter Filter) {
 	if filter.Selection() == nil {
 		l.actions.SetActions()
 		l.footer.SetBindings()
-		l.viewport.SetContent("")
+		l.setPreview("")
 		return
 	}
 
@@ -194,7 +513,7 @@ func (l *List) updateSelection(filter Filter) {
 	l.actions.SetActions(item.Actions...)
 	if item.Preview != "" {
 		l.viewport.SetYOffset(0)
-		l.viewport.SetContent(item.Preview)
+		l.setPreview(item.Preview)
 	}
 
 	if len(item.Actions) == 0 {
@@ -211,6 +530,19 @@ func (c *List) Update(msg tea.Msg) (Page, tea.Cmd) {
 	var cmd tea.Cmd
 	var cmds []tea.Cmd
 
+	if msg, ok := msg.(tea.KeyMsg); ok && c.isToggleKey(msg) {
+		selection := c.filter.Selection()
+		c.ToggleSelection()
+		c.updateSelection(c.filter)
+		if newSelection := c.filter.Selection(); newSelection != nil && newSelection.ID() != selection.ID() {
+			c.stopPreview()
+			return c, tea.Tick(debounceDuration, func(t time.Time) tea.Msg {
+				return SelectionChangeMsg{SelectionId: newSelection.ID()}
+			})
+		}
+		return c, nil
+	}
+
 	header, cmd := c.header.Update(msg)
 	cmds = append(cmds, cmd)
 
@@ -236,6 +568,16 @@ func (c *List) Update(msg tea.Msg) (Page, tea.Cmd) {
 		case tea.KeyShiftUp:
 			c.viewport.LineUp(1)
 			return c, nil
+		case tea.KeyPgDown:
+			c.viewport.HalfViewDown()
+			return c, nil
+		case tea.KeyPgUp:
+			c.viewport.HalfViewUp()
+			return c, nil
+		}
+
+		if msg.String() == previewConfig.ToggleKey && c.ShowPreview && !c.actions.Focused() {
+			return c, c.TogglePreview()
 		}
 	case UpdateQueryMsg:
 		if !c.IsGenerator {
@@ -247,7 +589,9 @@ func (c *List) Update(msg tea.Msg) (Page, tea.Cmd) {
 
 		return c, NewReloadPageCmd(nil)
 	case SelectionChangeMsg:
-		if !c.ShowPreview {
+		// Previews hidden by the user are loaded once they are displayed again.
+		// The ones hidden because the terminal is too narrow are still loaded, since the terminal can be resized.
+		if !c.ShowPreview || c.previewHidden {
 			return c, nil
 		}
 
@@ -264,14 +608,24 @@ func (c *List) Update(msg tea.Msg) (Page, tea.Cmd) {
 			return c, nil
 		}
 
-		return c, tea.Sequence(c.header.SetIsLoading(true), func() tea.Msg {
-			return PreviewContentMsg(item.PreviewCmd())
-		})
+		return c, c.loadPreview(item)
+
+	case ListPreviewMsg:
+		// Ignore the output of superseded previews
+		if msg.run != c.previewCtx {
+			return c, nil
+		}
 
-	case PreviewContentMsg:
-		c.header.SetIsLoading(false)
+		c.previewCtx, c.cancelPreview = nil, nil
+		c.header.SetIsLoading(c.isLoading)
 		c.viewport.SetYOffset(0)
-		c.viewport.SetContent(string(msg))
+		if msg.Err != nil {
+			c.setPreview(msg.Err.Error())
+			return c, nil
+		}
+
+		previewCache.Set(c.previewKey(msg.ItemId), msg.Content, msg.ttl)
+		c.setPreview(msg.Content)
 		return c, nil
 	}
 
@@ -286,10 +640,11 @@ func (c *List) Update(msg tea.Msg) (Page, tea.Cmd) {
 		cmds = append(cmds, tea.Tick(debounceDuration, func(t time.Time) tea.Msg {
 			return UpdateQueryMsg{Query: header.Value()}
 		}))
-		filter.FilterItems(header.Value())
+		filter.FilterItems(c.filterQuery(header.Value()))
 	}
 
-	if c.filter.Selection() != nil && filter.Selection() != nil && filter.Selection().ID() != c.filter.Selection().ID() {
+	selectionChanged := c.filter.Selection() != nil && filter.Selection() != nil && filter.Selection().ID() != c.filter.Selection().ID()
+	if selectionChanged {
 		cmds = append(cmds, tea.Tick(debounceDuration, func(t time.Time) tea.Msg {
 			return SelectionChangeMsg{SelectionId: filter.Selection().ID()}
 		}))
@@ -299,6 +654,9 @@ func (c *List) Update(msg tea.Msg) (Page, tea.Cmd) {
 	c.header = header
 	c.filter = filter
 	c.updateSelection(c.filter)
+	if selectionChanged {
+		c.stopPreview()
+	}
 
 	return c, tea.Batch(cmds...)
 }
@@ -311,12 +669,35 @@ type UpdateQueryMsg struct {
 	Query string
 }
 
+// TogglePreview shows or hides the preview, the preview of the selection is loaded when it is shown.
+func (c *List) TogglePreview() tea.Cmd {
+	c.previewHidden = !c.previewHidden
+	if c.previewHidden {
+		c.stopPreview()
+	}
+	c.SetSize(c.width, c.height)
+
+	selection := c.filter.Selection()
+	if !c.previewVisible() || selection == nil {
+		return nil
+	}
+
+	return func() tea.Msg {
+		return SelectionChangeMsg{SelectionId: selection.ID()}
+	}
+}
+
 func (c List) View() string {
 	if c.actions.Focused() {
 		return c.actions.View()
 	}
 
-	if c.ShowPreview {
+	if c.previewVisible() && c.PreviewLayout.Position == "bottom" {
+		separator := strings.Repeat("─", c.width)
+		return lipgloss.JoinVertical(lipgloss.Left, c.header.View(), c.filter.View(), separator, c.viewport.View(), c.footer.View())
+	}
+
+	if c.previewVisible() {
 		var separatorChars = make([]string, c.viewport.Height)
 		for i := 0; i < c.viewport.Height; i++ {
 			separatorChars[i] = "│"
@@ -330,6 +711,15 @@ func (c List) View() string {
 	return lipgloss.JoinVertical(lipgloss.Left, c.header.View(), c.filter.View(), c.footer.View())
 }
 
+// filterQuery returns the query used to filter the items locally.
+// Generator lists are filtered by the command itself, so their items are displayed as is.
+func (c List) filterQuery(query string) string {
+	if c.IsGenerator {
+		return ""
+	}
+	return query
+}
+
 func (c List) Query() string {
 	return c.header.input.Value()
 }
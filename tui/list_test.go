@@ -1,6 +1,18 @@
 package tui
 
-import "testing"
+import (
+	"context"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+var ansiPattern = regexp.MustCompile("\x1b\\[[0-9;]*m")
 
 func TestItemView(t *testing.T) {
 	type testCase struct {
@@ -22,35 +34,233 @@ func TestItemView(t *testing.T) {
 		},
 		"accessories truncated": {
 			item:  item,
-			width: 2,
-			want:  "Ti",
+			width: 9,
+			want:  "  Title 3",
 		},
 		"title truncated": {
 			item:  item,
-			width: 7,
-			want:  "Tit 31*",
+			width: 4,
+			want:  "  Ti",
 		},
 		"subtitle truncated": {
 			item:  item,
-			width: 13,
-			want:  "Title Sub 31*",
+			width: 15,
+			want:  "  Title Sub 31*",
 		},
 		"list expanded": {
 			item:  item,
-			width: 20,
-			want:  "Title Subtitle   31*",
+			width: 22,
+			want:  "  Title Subtitle   31*",
+		},
+		"highlighted": {
+			item:  item.Highlight([]int{0, 6}).(ListItem),
+			width: 22,
+			want:  "  Title Subtitle   31*",
 		},
 	}
 
 	for key, c := range cases {
+		c := c
 		t.Run(key, func(t *testing.T) {
 			if c.width != len(c.want) {
 				t.Errorf("test case width (%d) does not match expected length (%d)", c.width, len(c.want))
 			}
-			got := c.item.Render(c.width, false)
+			got := ansiPattern.ReplaceAllString(c.item.Render(c.width, false), "")
 			if got != c.want {
 				t.Errorf("got %q, want %q", got, c.want)
 			}
 		})
 	}
 }
+
+func TestItemHighlight(t *testing.T) {
+	defer lipgloss.SetColorProfile(lipgloss.ColorProfile())
+	lipgloss.SetColorProfile(termenv.ANSI)
+
+	item := ListItem{
+		Title:    "Title",
+		Subtitle: "Subtitle",
+	}
+
+	// The matched indexes are offsets in the filter value, "Title Subtitle"
+	got := item.Highlight([]int{0, 6}).Render(20, false)
+	for _, matched := range []string{"T", "S"} {
+		if !strings.Contains(got, styles.Match.Render(matched)) {
+			t.Errorf("%q is not highlighted in %q", matched, got)
+		}
+	}
+
+	if got == item.Render(20, false) {
+		t.Errorf("highlighted item is rendered as the original item")
+	}
+}
+
+func TestMatchValues(t *testing.T) {
+	values := []string{"Clone Repository", "List Pull Requests", "Commit Changes", "list-repos"}
+
+	type testCase struct {
+		mode  string
+		query string
+		want  []int
+	}
+
+	cases := map[string]testCase{
+		"fuzzy":               {mode: "fuzzy", query: "lpreq", want: []int{1}},
+		"exact":               {mode: "exact", query: "REPO", want: []int{0, 3}},
+		"prefix":              {mode: "prefix", query: "list", want: []int{1, 3}},
+		"prefix is anchored":  {mode: "prefix", query: "repo", want: []int{}},
+		"extended exact":      {mode: "extended", query: "'change", want: []int{2}},
+		"extended prefix":     {mode: "extended", query: "^list", want: []int{1, 3}},
+		"extended suffix":     {mode: "extended", query: "s$", want: []int{1, 2, 3}},
+		"extended inverse":    {mode: "extended", query: "^list !pull", want: []int{3}},
+		"extended equal":      {mode: "extended", query: "^list-repos$", want: []int{3}},
+		"extended fuzzy":      {mode: "extended", query: "cmt", want: []int{2}},
+		"extended combined":   {mode: "extended", query: "^c !'commit", want: []int{0}},
+		"unknown mode":        {mode: "unknown", query: "lpreq", want: []int{1}},
+		"extended empty term": {mode: "extended", query: "!", want: []int{0, 1, 2, 3}},
+	}
+
+	for key, c := range cases {
+		c := c
+		t.Run(key, func(t *testing.T) {
+			indexes := make([]int, 0)
+			for _, match := range MatchValues(c.mode, c.query, values) {
+				indexes = append(indexes, match.Index)
+			}
+
+			if !reflect.DeepEqual(indexes, c.want) {
+				t.Errorf("got %v, want %v", indexes, c.want)
+			}
+		})
+	}
+
+	t.Run("matched indexes", func(t *testing.T) {
+		matches := MatchValues("exact", "repo", []string{"list-repos"})
+		want := []int{5, 6, 7, 8}
+		if len(matches) != 1 || !reflect.DeepEqual(matches[0].MatchedIndexes, want) {
+			t.Errorf("got %v, want %v", matches, want)
+		}
+	})
+}
+
+func newMultiSelectList() *List {
+	list := NewList("Issues")
+	list.MultiSelect = true
+	list.SetSize(80, 20)
+	list.SetItems([]ListItem{
+		{Id: "1", Title: "First Issue", Payload: map[string]any{"number": 1}},
+		{Id: "2", Title: "Second Issue"},
+		{Id: "3", Title: "Third Issue"},
+	})
+	return list
+}
+
+func TestToggleSelection(t *testing.T) {
+	list := newMultiSelectList()
+
+	list.ToggleSelection()
+	list.ToggleSelection()
+	if selection := list.Selection(); selection == nil || selection.Id != "3" {
+		t.Errorf("the cursor should move to the next item, got %v", selection)
+	}
+
+	checkedIds := func() []string {
+		ids := make([]string, 0)
+		for _, item := range list.CheckedItems() {
+			ids = append(ids, item.Id)
+		}
+		return ids
+	}
+
+	if got, want := checkedIds(), []string{"1", "2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// Unchecking the second item
+	list.filter.CursorUp()
+	list.ToggleSelection()
+	if got, want := checkedIds(), []string{"1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestToggleKey(t *testing.T) {
+	list := newMultiSelectList()
+	list.Init()
+	space := tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+
+	list.Update(space)
+	if len(list.CheckedItems()) != 1 {
+		t.Errorf("space should toggle the selection while the query is empty")
+	}
+
+	for _, msg := range []tea.KeyMsg{{Type: tea.KeyRunes, Runes: []rune("second")}, space, {Type: tea.KeyRunes, Runes: []rune("issue")}} {
+		list.Update(msg)
+	}
+	if list.Query() != "second issue" {
+		t.Errorf("space should be typed in the query, got %q", list.Query())
+	}
+	if len(list.CheckedItems()) != 1 {
+		t.Errorf("space should not toggle the selection while the query is not empty")
+	}
+
+	list.Update(tea.KeyMsg{Type: tea.KeyCtrlAt})
+	if len(list.CheckedItems()) != 2 {
+		t.Errorf("ctrl+space should toggle the selection")
+	}
+}
+
+func TestBatchInput(t *testing.T) {
+	list := newMultiSelectList()
+
+	// The selected item is used if no item is checked
+	input, err := list.BatchInput()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "[{\"number\":1}]\n"; input != want {
+		t.Errorf("got %q, want %q", input, want)
+	}
+
+	list.ToggleSelection()
+	list.filter.CursorDown()
+	list.ToggleSelection()
+
+	input, err = list.BatchInput()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "[{\"number\":1},\"3\"]\n"; input != want {
+		t.Errorf("got %q, want %q", input, want)
+	}
+}
+
+func TestPreviewLoading(t *testing.T) {
+	list := NewList("Issues")
+	list.SetItems([]ListItem{
+		{Id: "1", Title: "First Issue", PreviewCmd: func(ctx context.Context) (string, error) {
+			return "Preview", nil
+		}},
+	})
+
+	// The command of the page is still running, for example while items are streamed
+	list.SetIsLoading(true)
+	list.loadPreview(*list.Selection())
+	list.stopPreview()
+	if !list.header.isLoading {
+		t.Errorf("cancelling the preview should not hide the spinner of the command")
+	}
+
+	if cmd := list.loadPreview(*list.Selection()); cmd == nil {
+		t.Fatal("expected a preview command")
+	}
+	list.Update(ListPreviewMsg{ItemId: "1", Content: "Preview", run: list.previewCtx})
+	if !list.header.isLoading {
+		t.Errorf("the output of the preview should not hide the spinner of the command")
+	}
+
+	list.SetIsLoading(false)
+	if list.header.isLoading {
+		t.Errorf("the spinner should be hidden once the command is done")
+	}
+}
@@ -0,0 +1,264 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sahilm/fuzzy"
+)
+
+// MatchModes are the ways a query can match the items of a list
+var MatchModes = []string{"fuzzy", "exact", "prefix", "extended"}
+
+// defaultMatchMode is used by lists which do not set a match mode, it can be set in the user config
+var defaultMatchMode = "fuzzy"
+
+// SetMatchMode sets the match mode of the lists which do not set one.
+func SetMatchMode(mode string) error {
+	if mode == "" {
+		return nil
+	}
+	if err := validateMatchMode(mode); err != nil {
+		return err
+	}
+
+	defaultMatchMode = mode
+	return nil
+}
+
+func validateMatchMode(mode string) error {
+	for _, matchMode := range MatchModes {
+		if mode == matchMode {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid match mode: %s, expected one of %s", mode, strings.Join(MatchModes, ", "))
+}
+
+// Match is a value matching a query.
+// MatchedIndexes are the byte offsets of the matched runes in the value, used to highlight them.
+type Match struct {
+	Index          int
+	MatchedIndexes []int
+	Score          int
+}
+
+// MatchValues returns the values matching the query, sorted by relevance.
+// An unknown mode falls back to fuzzy matching.
+func MatchValues(mode string, query string, values []string) []Match {
+	switch mode {
+	case "exact":
+		return matchEach(values, func(value string) ([]int, bool) {
+			start, end := indexFold(value, query)
+			if start == -1 {
+				return nil, false
+			}
+			return runeIndexes(value, start, end), true
+		})
+	case "prefix":
+		return matchEach(values, func(value string) ([]int, bool) {
+			end, ok := hasPrefixFold(value, query)
+			if !ok {
+				return nil, false
+			}
+			return runeIndexes(value, 0, end), true
+		})
+	case "extended":
+		return matchExtended(query, values)
+	default:
+		return matchFuzzy(query, values)
+	}
+}
+
+func matchFuzzy(query string, values []string) []Match {
+	fuzzyMatches := fuzzy.Find(query, values)
+	matches := make([]Match, len(fuzzyMatches))
+	for i, match := range fuzzyMatches {
+		matches[i] = Match{
+			Index:          match.Index,
+			MatchedIndexes: match.MatchedIndexes,
+			Score:          match.Score,
+		}
+	}
+
+	return matches
+}
+
+// matchEach keeps the values accepted by the match function, in their original order.
+func matchEach(values []string, match func(value string) ([]int, bool)) []Match {
+	matches := make([]Match, 0)
+	for i, value := range values {
+		if matchedIndexes, ok := match(value); ok {
+			matches = append(matches, Match{Index: i, MatchedIndexes: matchedIndexes})
+		}
+	}
+	return matches
+}
+
+// matchExtended implements the extended search syntax of fzf.
+// Values must match every space separated term of the query:
+//   - 'term matches values containing term
+//   - ^term matches values starting with term
+//   - term$ matches values ending with term
+//   - !term matches values which do not contain term, it can be combined with ^ and $
+//   - any other term is matched fuzzily
+func matchExtended(query string, values []string) []Match {
+	matches := make([]Match, len(values))
+	matched := make([]bool, len(values))
+	for i := range values {
+		matches[i] = Match{Index: i}
+		matched[i] = true
+	}
+
+	hasFuzzyTerm := false
+	for _, term := range strings.Fields(query) {
+		inverse := strings.HasPrefix(term, "!")
+		term = strings.TrimPrefix(term, "!")
+
+		var match func(value string) (int, int)
+		switch {
+		case strings.HasPrefix(term, "'"):
+			term = strings.TrimPrefix(term, "'")
+			match = func(value string) (int, int) {
+				return indexFold(value, term)
+			}
+		case strings.HasPrefix(term, "^") && strings.HasSuffix(term, "$") && len(term) > 1:
+			term = strings.TrimSuffix(strings.TrimPrefix(term, "^"), "$")
+			match = func(value string) (int, int) {
+				if end, ok := hasPrefixFold(value, term); ok && end == len(value) {
+					return 0, end
+				}
+				return -1, -1
+			}
+		case strings.HasPrefix(term, "^"):
+			term = strings.TrimPrefix(term, "^")
+			match = func(value string) (int, int) {
+				if end, ok := hasPrefixFold(value, term); ok {
+					return 0, end
+				}
+				return -1, -1
+			}
+		case strings.HasSuffix(term, "$"):
+			term = strings.TrimSuffix(term, "$")
+			match = func(value string) (int, int) {
+				return suffixFold(value, term)
+			}
+		case !inverse:
+			if term == "" {
+				continue
+			}
+
+			// Fuzzy terms are matched against all values at once
+			hasFuzzyTerm = true
+			fuzzyMatches := make(map[int]fuzzy.Match)
+			for _, fuzzyMatch := range fuzzy.Find(term, values) {
+				fuzzyMatches[fuzzyMatch.Index] = fuzzyMatch
+			}
+
+			for i := range values {
+				fuzzyMatch, ok := fuzzyMatches[i]
+				if !ok {
+					matched[i] = false
+					continue
+				}
+				matches[i].Score += fuzzyMatch.Score
+				matches[i].MatchedIndexes = append(matches[i].MatchedIndexes, fuzzyMatch.MatchedIndexes...)
+			}
+			continue
+		default:
+			// A term prefixed with ! only is an inverse exact match
+			match = func(value string) (int, int) {
+				return indexFold(value, term)
+			}
+		}
+
+		if term == "" {
+			continue
+		}
+
+		for i, value := range values {
+			if !matched[i] {
+				continue
+			}
+
+			start, end := match(value)
+			if inverse {
+				matched[i] = start == -1
+				continue
+			}
+			if start == -1 {
+				matched[i] = false
+				continue
+			}
+			matches[i].MatchedIndexes = append(matches[i].MatchedIndexes, runeIndexes(value, start, end)...)
+		}
+	}
+
+	filtered := make([]Match, 0)
+	for i, match := range matches {
+		if matched[i] {
+			filtered = append(filtered, match)
+		}
+	}
+
+	// Values are sorted by relevance only if a term was matched fuzzily, like fzf does
+	if hasFuzzyTerm {
+		sort.SliceStable(filtered, func(i, j int) bool {
+			return filtered[i].Score > filtered[j].Score
+		})
+	}
+
+	return filtered
+}
+
+// hasPrefixFold reports whether s starts with prefix, ignoring case, and returns the byte offset of the end of the prefix in s.
+func hasPrefixFold(s, prefix string) (int, bool) {
+	end := 0
+	for _, prefixRune := range prefix {
+		if end >= len(s) {
+			return 0, false
+		}
+
+		r, size := utf8.DecodeRuneInString(s[end:])
+		if !strings.EqualFold(string(r), string(prefixRune)) {
+			return 0, false
+		}
+		end += size
+	}
+
+	return end, true
+}
+
+// indexFold returns the byte offsets of the first occurrence of substr in s, ignoring case, or -1 if there is none.
+func indexFold(s, substr string) (int, int) {
+	for start := range s {
+		if end, ok := hasPrefixFold(s[start:], substr); ok {
+			return start, start + end
+		}
+	}
+
+	return -1, -1
+}
+
+// suffixFold returns the byte offsets of suffix at the end of s, ignoring case, or -1 if s does not end with it.
+func suffixFold(s, suffix string) (int, int) {
+	for start := range s {
+		if end, ok := hasPrefixFold(s[start:], suffix); ok && start+end == len(s) {
+			return start, len(s)
+		}
+	}
+
+	return -1, -1
+}
+
+// runeIndexes returns the byte offsets of the runes of s between start and end.
+func runeIndexes(s string, start, end int) []int {
+	indexes := make([]int, 0, end-start)
+	for i := range s[start:end] {
+		indexes = append(indexes, start+i)
+	}
+	return indexes
+}
@@ -0,0 +1,75 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pomdtr/sunbeam/app"
+)
+
+// The metadata is displayed below the text when the terminal is narrower than this width
+const metadataSidebarMinWidth = 80
+
+var tagColors = map[string]string{
+	"black":   "0",
+	"red":     "1",
+	"green":   "2",
+	"yellow":  "3",
+	"blue":    "4",
+	"magenta": "5",
+	"cyan":    "6",
+	"white":   "7",
+	"gray":    "8",
+}
+
+// tagColor accepts either a color name, an ansi color code or a hex color.
+func tagColor(color string) lipgloss.Color {
+	if code, ok := tagColors[color]; ok {
+		return lipgloss.Color(code)
+	}
+	if color == "" {
+		return lipgloss.Color("8")
+	}
+	return lipgloss.Color(color)
+}
+
+func renderMetadataValue(item app.MetadataItem) string {
+	values := make([]string, 0)
+	if item.Value != "" {
+		value := item.Value
+		if item.Url != "" {
+			value = lipgloss.NewStyle().Underline(true).Foreground(lipgloss.Color("4")).Render(value)
+		}
+		values = append(values, value)
+	}
+
+	for _, tag := range item.Tags {
+		values = append(values, lipgloss.NewStyle().Background(tagColor(tag.Color)).Foreground(lipgloss.Color("0")).Padding(0, 1).Render(tag.Text))
+	}
+
+	return strings.Join(values, " ")
+}
+
+// RenderMetadataSidebar renders each item as a label followed by its value, wrapped to the width of the sidebar.
+func RenderMetadataSidebar(items []app.MetadataItem, width int) string {
+	rows := make([]string, 0, len(items))
+	for _, item := range items {
+		label := styles.Faint.Render(item.Label)
+		value := lipgloss.NewStyle().Width(width).Render(renderMetadataValue(item))
+		rows = append(rows, lipgloss.JoinVertical(lipgloss.Left, label, value))
+	}
+
+	return lipgloss.NewStyle().Padding(0, 1).Render(strings.Join(rows, "\n\n"))
+}
+
+// RenderMetadataInline renders each item on a single line, to be displayed below the text.
+func RenderMetadataInline(items []app.MetadataItem, width int) string {
+	rows := make([]string, 0, len(items))
+	for _, item := range items {
+		row := fmt.Sprintf("%s %s", styles.Faint.Render(item.Label+":"), renderMetadataValue(item))
+		rows = append(rows, lipgloss.NewStyle().MaxWidth(width).Render(row))
+	}
+
+	return lipgloss.NewStyle().Padding(0, 1).Render(strings.Join(rows, "\n"))
+}
@@ -93,7 +93,7 @@ func (k *KeyStore) SetPreference(preferences ...ScriptPreference) error {
 		k.preferenceMap[GetPreferenceId(preference.Extension, preference.Command, preference.Name)] = preference
 	}
 
-	return keyStore.Save()
+	return k.Save()
 }
 
 // TODO: Remove this
@@ -0,0 +1,97 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alecthomas/chroma/quick"
+	"github.com/charmbracelet/glamour"
+	"github.com/pomdtr/sunbeam/app"
+)
+
+// PreviewConfig is the default layout of list previews, read from the user config.
+type PreviewConfig struct {
+	app.PreviewLayout `yaml:",inline"`
+	// ToggleKey shows or hides the preview
+	ToggleKey string `yaml:"toggleKey"`
+}
+
+var previewConfig = PreviewConfig{
+	PreviewLayout: app.PreviewLayout{
+		Position: "right",
+		Ratio:    2.0 / 3,
+		MinWidth: 60,
+	},
+	ToggleKey: "ctrl+p",
+}
+
+// SetPreviewConfig overrides the default layout of list previews with the values set in the user config.
+func SetPreviewConfig(config PreviewConfig) error {
+	layout, err := mergePreviewLayout(previewConfig.PreviewLayout, config.PreviewLayout)
+	if err != nil {
+		return err
+	}
+
+	previewConfig.PreviewLayout = layout
+	if config.ToggleKey != "" {
+		previewConfig.ToggleKey = config.ToggleKey
+	}
+
+	return nil
+}
+
+// mergePreviewLayout returns the layout with the values set in the override.
+func mergePreviewLayout(layout app.PreviewLayout, override app.PreviewLayout) (app.PreviewLayout, error) {
+	switch override.Position {
+	case "":
+	case "right", "bottom":
+		layout.Position = override.Position
+	default:
+		return layout, fmt.Errorf("invalid preview position: %s", override.Position)
+	}
+
+	if override.Ratio != 0 {
+		if override.Ratio < 0 || override.Ratio >= 1 {
+			return layout, fmt.Errorf("invalid preview ratio: %v, it must be between 0 and 1", override.Ratio)
+		}
+		layout.Ratio = override.Ratio
+	}
+
+	if override.MinWidth != 0 {
+		layout.MinWidth = override.MinWidth
+	}
+
+	return layout, nil
+}
+
+// RenderPreview renders the content of a preview according to its format.
+// Content which fails to render is displayed as is.
+func RenderPreview(content string, format string, language string, width int) string {
+	switch format {
+	case "markdown":
+		// The preview is rendered again once its size is known
+		if width <= 0 {
+			return content
+		}
+
+		renderer, err := glamour.NewTermRenderer(glamour.WithStandardStyle("dark"), glamour.WithWordWrap(width))
+		if err != nil {
+			return content
+		}
+
+		rendered, err := renderer.Render(content)
+		if err != nil {
+			return content
+		}
+		return rendered
+	case "code":
+		var builder strings.Builder
+		if err := quick.Highlight(&builder, content, language, "terminal256", "monokai"); err != nil {
+			return content
+		}
+		return builder.String()
+	default:
+		// Text and ansi content is displayed as is, the viewport handles ansi escape codes
+		return content
+	}
+}
@@ -5,18 +5,35 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"os/exec"
 	"path"
+	"sort"
+	"strings"
 	"time"
 
-	"github.com/atotto/clipboard"
+	"github.com/alessio/shellescape"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/pkg/browser"
 	"github.com/pomdtr/sunbeam/app"
+	"github.com/pomdtr/sunbeam/utils"
 )
 
 type Config struct {
 	RootItems []app.RootItem `yaml:"rootItems"`
+	Preview   PreviewConfig  `yaml:"preview"`
+	MatchMode string         `yaml:"matchMode"`
+	// OpenCommand opens the paths of open-path actions, instead of the system opener
+	OpenCommand string          `yaml:"openCommand"`
+	Clipboard   ClipboardConfig `yaml:"clipboard"`
+}
+
+// openCommand is set from the user config, the system opener is used if it is empty
+var openCommand string
+
+// SetOpenCommand sets the command opening the paths of open-path actions.
+func SetOpenCommand(command string) {
+	openCommand = command
 }
 
 type Page interface {
@@ -26,6 +43,11 @@ type Page interface {
 	SetSize(width, height int)
 }
 
+// Cancellable pages own running processes, which are killed when the page is closed.
+type Cancellable interface {
+	Cancel()
+}
+
 type Model struct {
 	width, height int
 
@@ -34,6 +56,10 @@ type Model struct {
 
 	hidden bool
 	exit   bool
+
+	// toast is displayed over the footer of the current page, toastId identifies it to hide it once it expires
+	toast   *Toast
+	toastId int
 }
 
 func NewModel(root Page) *Model {
@@ -52,9 +78,8 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.Type {
-		case tea.KeyEscape:
-			fmt.Sprintln("Escape")
 		case tea.KeyCtrlC:
+			m.cancelPages()
 			m.hidden = true
 			m.exit = true
 			return m, tea.Quit
@@ -62,20 +87,76 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.SetSize(msg.Width, msg.Height)
 		return m, nil
+	case ShowToastMsg:
+		m.toastId++
+		m.toast = &msg.Toast
+
+		id := m.toastId
+		return m, tea.Tick(toastDuration, func(time.Time) tea.Msg {
+			return hideToastMsg{id: id}
+		})
+	case hideToastMsg:
+		// A newer toast is kept until its own duration expires
+		if msg.id == m.toastId {
+			m.toast = nil
+		}
+		return m, nil
 	case OpenUrlMsg:
 		err := browser.OpenURL(msg.Url)
+		if !msg.Exit {
+			if err != nil {
+				return m, NewToastCmd(ToastFailure, fmt.Sprintf("Failed to open %s: %s", msg.Url, err))
+			}
+			return m, NewToastCmd(ToastSuccess, "Opened in Browser")
+		}
 		if err != nil {
 			return m, NewErrorCmd(err)
 		}
 
+		m.cancelPages()
 		m.hidden = true
 		return m, tea.Quit
+	case OpenPathMsg:
+		err := openPath(msg.Path)
+		if !msg.Exit {
+			if err != nil {
+				return m, NewToastCmd(ToastFailure, fmt.Sprintf("Failed to open %s: %s", msg.Path, err))
+			}
+			return m, NewToastCmd(ToastSuccess, fmt.Sprintf("Opened %s", path.Base(msg.Path)))
+		}
+		if err != nil {
+			return m, NewErrorCmd(fmt.Errorf("failed to open %s: %s", msg.Path, err))
+		}
+
+		m.cancelPages()
+		m.hidden = true
+		return m, tea.Quit
+	case EditMsg:
+		cmd, err := editCmd(msg.Path, msg.Line)
+		if err != nil {
+			return m, NewErrorCmd(err)
+		}
+
+		// The tui is suspended while the editor is running, and restored once it exits
+		return m, tea.ExecProcess(cmd, func(err error) tea.Msg {
+			if err != nil {
+				return fmt.Errorf("failed to edit %s: %s", msg.Path, err)
+			}
+			return nil
+		})
 	case CopyTextMsg:
-		err := clipboard.WriteAll(msg.Text)
+		err := systemClipboard.Copy(msg.Text)
+		if !msg.Exit {
+			if err != nil {
+				return m, NewToastCmd(ToastFailure, fmt.Sprintf("Failed to copy text to clipboard: %s", err))
+			}
+			return m, NewToastCmd(ToastSuccess, "Copied to Clipboard")
+		}
 		if err != nil {
 			return m, NewErrorCmd(fmt.Errorf("failed to copy text to clipboard: %s", err))
 		}
 
+		m.cancelPages()
 		m.hidden = true
 		return m, tea.Quit
 	case PushPageMsg:
@@ -86,6 +167,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, cmd
 	case popMsg:
 		if len(m.pages) == 0 {
+			m.cancelPages()
 			return m, tea.Quit
 		} else {
 			m.Pop()
@@ -94,11 +176,13 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case error:
 		detail := NewDetail("Error")
 		detail.SetSize(m.width, m.pageHeight())
-		detail.viewport.SetContent(msg.Error())
+		detail.SetContent(msg.Error())
 
 		if len(m.pages) == 0 {
+			cancelPage(m.root)
 			m.root = detail
 		} else {
+			cancelPage(m.pages[len(m.pages)-1])
 			m.pages[len(m.pages)-1] = detail
 		}
 
@@ -123,13 +207,22 @@ func (m *Model) View() string {
 		return ""
 	}
 
+	var view string
 	if len(m.pages) > 0 {
 		currentPage := m.pages[len(m.pages)-1]
-		return currentPage.View()
+		view = currentPage.View()
+	} else {
+		view = m.root.View()
 	}
 
-	return m.root.View()
+	if m.toast == nil {
+		return view
+	}
 
+	// The toast replaces the last line of the page, which is the footer
+	lines := strings.Split(view, "\n")
+	lines[len(lines)-1] = m.toast.View(m.width)
+	return strings.Join(lines, "\n")
 }
 
 func (m *Model) SetSize(width, height int) {
@@ -173,10 +266,24 @@ func (m *Model) Push(page Page) tea.Cmd {
 
 func (m *Model) Pop() {
 	if len(m.pages) > 0 {
+		cancelPage(m.pages[len(m.pages)-1])
 		m.pages = m.pages[:len(m.pages)-1]
 	}
 }
 
+func (m *Model) cancelPages() {
+	cancelPage(m.root)
+	for _, page := range m.pages {
+		cancelPage(page)
+	}
+}
+
+func cancelPage(page Page) {
+	if page, ok := page.(Cancellable); ok {
+		page.Cancel()
+	}
+}
+
 func loadHistory(historyPath string) map[string]int64 {
 	history := make(map[string]int64)
 	data, err := os.ReadFile(historyPath)
@@ -198,6 +305,7 @@ func NewRootList(extensionMap map[string]app.Extension, additionalItems ...app.R
 	historyPath := path.Join(stateDir, "history.json")
 	history := loadHistory(historyPath)
 	list := NewList("Sunbeam")
+	list.filter.UngroupedSearch = true
 	list.filter.Less = func(i, j FilterItem) bool {
 		iValue, ok := history[i.ID()]
 		if !ok {
@@ -232,6 +340,11 @@ func NewRootList(extensionMap map[string]app.Extension, additionalItems ...app.R
 		})
 	}
 
+	// Extensions are iterated in random order, sort them to keep the order of the sections stable
+	sort.SliceStable(rootItems, func(i, j int) bool {
+		return rootItems[i].Extension < rootItems[j].Extension
+	})
+
 	listItems := make([]ListItem, 0)
 	for _, rootItem := range rootItems {
 		rootItem := rootItem
@@ -241,6 +354,7 @@ func NewRootList(extensionMap map[string]app.Extension, additionalItems ...app.R
 			Title:       rootItem.Title,
 			Subtitle:    extension.Title,
 			Accessories: []string{rootItem.Extension},
+			Section:     extension.Title,
 			Actions: []Action{
 				{
 					Title:    "Run Command",
@@ -313,9 +427,49 @@ func Draw(model *Model, fullscreen bool) (err error) {
 	}
 
 	_, err = p.Run()
+	// Pages can also quit the program, their commands must not outlive it
+	model.cancelPages()
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
+
+func openPath(filepath string) error {
+	filepath, err := utils.ResolvePath(filepath)
+	if err != nil {
+		return err
+	}
+
+	if openCommand == "" {
+		return browser.OpenFile(filepath)
+	}
+
+	// The command is not waited for, since sunbeam exits right after
+	cmd := exec.Command("sh", "-c", fmt.Sprintf("%s %s", openCommand, shellescape.Quote(filepath)))
+	return cmd.Start()
+}
+
+// editCmd returns the command opening the file in $EDITOR, vi is used if it is not set.
+func editCmd(filepath string, line int) (*exec.Cmd, error) {
+	filepath, err := utils.ResolvePath(filepath)
+	if err != nil {
+		return nil, err
+	}
+
+	editor, ok := os.LookupEnv("EDITOR")
+	if !ok || editor == "" {
+		editor = "vi"
+	}
+
+	// The editor can contain arguments, like code --wait
+	args := []string{editor}
+	if line > 0 {
+		// Most terminal editors support the +line argument
+		args = append(args, fmt.Sprintf("+%d", line))
+	}
+	args = append(args, shellescape.Quote(filepath))
+
+	return exec.Command("sh", "-c", strings.Join(args, " ")), nil
+}
@@ -1,7 +1,9 @@
 package tui
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,8 +13,10 @@ import (
 	"os"
 	"os/exec"
 	"path"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -27,7 +31,26 @@ type CommandRunner struct {
 	extension NamedExtension
 	command   NamedCommand
 
-	with map[string]app.CommandInput
+	with               map[string]app.CommandInput
+	missingPreferences map[string]ScriptPreference
+
+	// ctx is cancelled when the runner is closed, killing every process it started
+	ctx       context.Context
+	cancel    context.CancelFunc
+	runCtx    context.Context
+	cancelRun context.CancelFunc
+
+	stream        *ListStream
+	streamedItems []ListItem
+
+	// submit is set when the form is a page returned by the command, instead of the inputs of its params
+	submit *app.FormSubmit
+
+	// input is passed on stdin, batch actions use it to send the selected items
+	input string
+
+	// request is set when the runner sends the request of an http action, instead of running a command
+	request *app.Action
 
 	header Header
 	footer Footer
@@ -62,7 +85,10 @@ func (c CommandRunner) CheckEnv() error {
 }
 
 func NewCommandRunner(extension NamedExtension, command NamedCommand, with map[string]app.CommandInput) *CommandRunner {
+	ctx, cancel := context.WithCancel(context.Background())
 	runner := CommandRunner{
+		ctx:         ctx,
+		cancel:      cancel,
 		header:      NewHeader(),
 		footer:      NewFooter(extension.Title),
 		extension:   extension,
@@ -78,14 +104,136 @@ func NewCommandRunner(extension NamedExtension, command NamedCommand, with map[s
 
 	return &runner
 }
+
+// NewRequestRunner returns a runner sending the request of an http action.
+// The response is handled like the output of a command, using the onSuccess and exit options of the action.
+func NewRequestRunner(extension NamedExtension, action app.Action) *CommandRunner {
+	method := action.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	runner := NewCommandRunner(extension, NamedCommand{
+		Name: fmt.Sprintf("%s %s", strings.ToUpper(method), action.Url),
+		Command: app.Command{
+			OnSuccess: action.OnSuccess,
+			Timeout:   action.Timeout,
+			Exit:      action.Exit,
+		},
+	}, action.With)
+	runner.request = &action
+
+	return runner
+}
+
 func (c *CommandRunner) Init() tea.Cmd {
 	return tea.Sequence(c.SetIsloading(true), c.Run())
 }
 
-type CommandOutput []byte
+// CommandOutput holds the output of a successful run.
+type CommandOutput struct {
+	Output []byte
+	run    context.Context
+}
 
-func (c *CommandRunner) Run() tea.Cmd {
+// CommandCancelledMsg is sent when a command is killed before completion, either by the user or because it timed out.
+type CommandCancelledMsg struct {
+	TimedOut bool
+	run      context.Context
+}
+
+type runAgainMsg struct{}
+
+// Cancel kills the processes started by the runner.
+func (c *CommandRunner) Cancel() {
+	c.cancel()
+}
+
+// runContext returns the context of a single run, which is done when the run is cancelled, when the runner is closed or when the command times out.
+// The previous run is cancelled, since its output would be replaced anyway.
+func (c *CommandRunner) runContext() (context.Context, context.CancelFunc) {
+	if c.cancelRun != nil {
+		c.cancelRun()
+	}
+
+	ctx, cancel := c.command.WithTimeout(c.ctx)
+	c.runCtx, c.cancelRun = ctx, cancel
+	return ctx, cancel
+}
+
+// cancelledMsg converts the error of a cancelled run to a message.
+// Runs stopped because the runner was closed are ignored, since the runner is not displayed anymore.
+func (c *CommandRunner) cancelledMsg(ctx context.Context) tea.Msg {
+	switch {
+	case c.ctx.Err() != nil:
+		return nil
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return CommandCancelledMsg{TimedOut: true, run: ctx}
+	default:
+		return CommandCancelledMsg{run: ctx}
+	}
+}
+
+func formatCommandError(err error) string {
+	var exitErr *exec.ExitError
+	if ok := errors.As(err, &exitErr); ok {
+		return fmt.Sprintf("command failed with exit code %d, error:\n%s", exitErr.ExitCode(), exitErr.Stderr)
+	}
+	return err.Error()
+}
+
+// ResolvePreferences loads the preferences declared by the extension and the command from the key store.
+// Preferences without a stored value are tracked as missing, and a form item is returned for each of them.
+func (c *CommandRunner) ResolvePreferences() (map[string]any, []FormItem) {
+	values := make(map[string]any)
 	formitems := make([]FormItem, 0)
+	c.missingPreferences = make(map[string]ScriptPreference)
+
+	overridden := make(map[string]bool)
+	for _, preference := range c.command.Preferences {
+		overridden[preference.Env] = true
+	}
+
+	resolve := func(command string, preference app.Preference) {
+		if stored, ok := keyStore.GetPreference(c.extension.Name, command, preference.Env); ok {
+			values[preference.Env] = stored.Value
+			return
+		}
+
+		id := fmt.Sprintf("preference:%s", preference.Env)
+		c.missingPreferences[id] = ScriptPreference{
+			Name:      preference.Env,
+			Command:   command,
+			Extension: c.extension.Name,
+		}
+
+		input := preference.Input
+		if input.Title == "" {
+			input.Title = preference.Env
+		}
+		formitems = append(formitems, NewFormItem(id, input))
+	}
+
+	for _, preference := range c.extension.Preferences {
+		if overridden[preference.Env] {
+			continue
+		}
+		resolve("", preference)
+	}
+
+	for _, preference := range c.command.Preferences {
+		resolve(c.command.Name, preference)
+	}
+
+	return values, formitems
+}
+
+func (c *CommandRunner) Run() tea.Cmd {
+	if c.request != nil {
+		return c.SendRequest()
+	}
+
+	preferences, formitems := c.ResolvePreferences()
 	for _, param := range c.command.Params {
 		input, ok := c.with[param.Name]
 		if !ok {
@@ -106,6 +254,7 @@ func (c *CommandRunner) Run() tea.Cmd {
 	// Show form if some parameters are set as input
 	if len(formitems) > 0 {
 		c.currentView = "form"
+		c.submit = nil
 		c.form = NewForm(c.extension.Title, formitems)
 
 		c.form.SetSize(c.width, c.height)
@@ -124,12 +273,17 @@ func (c *CommandRunner) Run() tea.Cmd {
 		return NewErrorCmd(err)
 	}
 
-	if err := c.command.CheckMissingParams(params); err != nil {
+	if _, err := c.command.ValidateParams(params); err != nil {
 		return NewErrorCmd(err)
 	}
 
 	commandInput := app.CommandParams{
-		With: params,
+		Input:       c.input,
+		With:        params,
+		Preferences: preferences,
+	}
+	if c.isGenerator() {
+		commandInput.Query = c.list.Query()
 	}
 
 	if c.extension.Root.Scheme != "file" {
@@ -137,7 +291,8 @@ func (c *CommandRunner) Run() tea.Cmd {
 			return NewErrorCmd(fmt.Errorf("interactive commands are not supported for remote extensions"))
 		}
 
-		return c.RemoteRun(commandInput)
+		ctx, cancel := c.runContext()
+		return c.RemoteRun(ctx, cancel, commandInput)
 	}
 
 	cmd, err := c.command.Cmd(commandInput, c.extension.Root.Path)
@@ -146,57 +301,275 @@ func (c *CommandRunner) Run() tea.Cmd {
 	}
 
 	if c.command.Interactive {
+		// The ui is suspended while the command runs, the context only identifies the run
+		ctx, cancel := c.runContext()
 		return tea.ExecProcess(cmd, func(err error) tea.Msg {
+			cancel()
 			if err != nil {
 				return err
 			}
-			return CommandOutput([]byte{})
+			return CommandOutput{Output: []byte{}, run: ctx}
 		})
 	}
 
+	ctx, cancel := c.runContext()
+	if c.command.OnSuccess == "push-page" {
+		return c.RunPage(ctx, cancel, cmd)
+	}
+
 	return func() tea.Msg {
-		output, err := cmd.Output()
+		defer cancel()
+		output, err := app.Output(ctx, cmd)
+		if ctx.Err() != nil {
+			return c.cancelledMsg(ctx)
+		}
 		if err != nil {
-			var exitErr *exec.ExitError
-			if ok := errors.As(err, &exitErr); ok {
-				return fmt.Errorf("command failed with exit code %d, error:\n%s", exitErr.ExitCode(), exitErr.Stderr)
-			}
-			return err
+			return errors.New(formatCommandError(err))
 		}
 
-		return CommandOutput(output)
+		return CommandOutput{Output: output, run: ctx}
 	}
 }
 
-func (c CommandRunner) RemoteRun(commandParams app.CommandParams) tea.Cmd {
+// SendRequest sends the request of an http action, the user is asked for the inputs without value first.
+func (c *CommandRunner) SendRequest() tea.Cmd {
+	names := make([]string, 0, len(c.with))
+	for name := range c.with {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	params := make(map[string]any)
+	formitems := make([]FormItem, 0)
+	for _, name := range names {
+		input := c.with[name]
+		if input.Value != nil {
+			params[name] = input.Value
+			continue
+		}
+
+		if input.FormItem.Title == "" {
+			input.FormItem.Title = name
+		}
+		formitems = append(formitems, NewFormItem(name, input.FormItem))
+	}
+
+	if len(formitems) > 0 {
+		c.currentView = "form"
+		c.submit = nil
+		c.form = NewForm(c.extension.Title, formitems)
+
+		c.form.SetSize(c.width, c.height)
+		return c.form.Init()
+	}
+
+	ctx, cancel := c.runContext()
+	request := *c.request
 	return func() tea.Msg {
-		payload, err := json.Marshal(commandParams)
+		defer cancel()
+		body, err := request.Send(ctx, params)
+		if ctx.Err() != nil {
+			return c.cancelledMsg(ctx)
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			return CommandCancelledMsg{TimedOut: true, run: ctx}
+		}
 		if err != nil {
 			return err
 		}
 
-		commandUrl := url.URL{
-			Scheme: c.extension.Root.Scheme,
-			Host:   c.extension.Root.Host,
-			Path:   path.Join(c.extension.Root.Path, c.command.Name),
-		}
-		res, err := http.Post(commandUrl.String(), "application/json", bytes.NewReader(payload))
+		return CommandOutput{Output: body, run: ctx}
+	}
+}
+
+// RunPage runs a command whose output is a page.
+// If the first line of the output is the header of a streaming list, the following lines are streamed as list items.
+func (c *CommandRunner) RunPage(ctx context.Context, cancel context.CancelFunc, cmd *exec.Cmd) tea.Cmd {
+	return func() tea.Msg {
+		stdout, wait, err := app.Stream(ctx, cmd)
 		if err != nil {
+			cancel()
 			return err
 		}
-		defer res.Body.Close()
 
-		if res.StatusCode != http.StatusOK {
-			return fmt.Errorf("command failed with status code %d", res.StatusCode)
+		reader := bufio.NewReader(stdout)
+		firstLine, _ := reader.ReadBytes('\n')
+
+		if header, ok, err := parseStreamHeader(firstLine); ok {
+			if err != nil {
+				cancel()
+				wait()
+				return err
+			}
+
+			return ListStreamMsg{
+				Page:   header,
+				Stream: NewListStream(ctx, cancel, reader, wait),
+				run:    ctx,
+			}
+		}
+
+		rest, _ := io.ReadAll(reader)
+		err = wait()
+		defer cancel()
+		if ctx.Err() != nil {
+			return c.cancelledMsg(ctx)
 		}
+		if err != nil {
+			return errors.New(formatCommandError(err))
+		}
+
+		return CommandOutput{Output: append(firstLine, rest...), run: ctx}
+	}
+}
 
-		body, err := io.ReadAll(res.Body)
+func (c *CommandRunner) RemoteRun(ctx context.Context, cancel context.CancelFunc, commandParams app.CommandParams) tea.Cmd {
+	return func() tea.Msg {
+		defer cancel()
+		body, err := c.post(ctx, c.command.Name, commandParams)
+		if ctx.Err() != nil {
+			return c.cancelledMsg(ctx)
+		}
 		if err != nil {
 			return err
 		}
 
-		return CommandOutput(body)
+		return CommandOutput{Output: body, run: ctx}
+	}
+}
+
+// post runs a command of a remote extension.
+func (c CommandRunner) post(ctx context.Context, command string, commandParams app.CommandParams) ([]byte, error) {
+	payload, err := json.Marshal(commandParams)
+	if err != nil {
+		return nil, err
+	}
+
+	commandUrl := url.URL{
+		Scheme: c.extension.Root.Scheme,
+		Host:   c.extension.Root.Host,
+		Path:   path.Join(c.extension.Root.Path, command),
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, commandUrl.String(), bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("command failed with status code %d:\n%s", res.StatusCode, body)
+	}
+
+	return body, nil
+}
+
+// Preview runs a preview command of the extension, and returns its output.
+// The error message is meant to be displayed in place of the preview.
+func (c CommandRunner) Preview(ctx context.Context, name string, with map[string]any) (string, error) {
+	command, ok := c.extension.Commands[name]
+	if !ok {
+		return "", fmt.Errorf("command %s not found", name)
+	}
+
+	ctx, cancel := command.WithTimeout(ctx)
+	defer cancel()
+
+	params := app.CommandParams{
+		With: with,
+	}
+
+	var output []byte
+	var err error
+	if c.extension.Root.Scheme != "file" {
+		output, err = c.post(ctx, name, params)
+	} else {
+		var cmd *exec.Cmd
+		cmd, err = command.Cmd(params, c.extension.Root.Path)
+		if err != nil {
+			return "", err
+		}
+
+		output, err = app.Output(ctx, cmd)
+	}
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return "", fmt.Errorf("preview timed out after %s", command.Timeout)
+	}
+	if err != nil {
+		return "", errors.New(formatCommandError(err))
+	}
+
+	return string(output), nil
+}
+
+// pageKey identifies the page in the preview cache, pages are the same if they are the output of the same command and params.
+func (c CommandRunner) pageKey() string {
+	with, _ := json.Marshal(c.with)
+	return fmt.Sprintf("%s/%s/%s", c.extension.Name, c.command.Name, with)
+}
+
+// resolvePaths makes the relative paths of actions relative to the extension root, instead of the working directory.
+// They are the paths of open-path and edit actions, and the certificates of http actions.
+func (c CommandRunner) resolvePaths(actions []app.Action) []app.Action {
+	if c.extension.Root.Scheme != "file" {
+		return actions
+	}
+
+	resolve := func(filepath string) string {
+		if filepath == "" || strings.HasPrefix(filepath, "~") || path.IsAbs(filepath) {
+			return filepath
+		}
+		return path.Join(c.extension.Root.Path, filepath)
+	}
+
+	resolved := make([]app.Action, len(actions))
+	for i, action := range actions {
+		switch action.Type {
+		case "open-path", "edit":
+			action.Path = resolve(action.Path)
+		case "http":
+			action.Tls.CaCert = resolve(action.Tls.CaCert)
+			action.Tls.Cert = resolve(action.Tls.Cert)
+			action.Tls.Key = resolve(action.Tls.Key)
+		}
+		resolved[i] = action
+	}
+
+	return resolved
+}
+
+func (c *CommandRunner) isGenerator() bool {
+	return c.currentView == "list" && c.list.IsGenerator
+}
+
+// newListItem converts an item of a list page, using its index as id if it has none.
+func (c *CommandRunner) newListItem(index int, scriptItem app.ListItem) ListItem {
+	if scriptItem.Id == "" {
+		scriptItem.Id = strconv.Itoa(index)
 	}
+
+	scriptItem.Actions = c.resolvePaths(scriptItem.Actions)
+	listItem := ParseScriptItem(scriptItem)
+	if scriptItem.Preview.Command != "" {
+		listItem.PreviewCmd = func(ctx context.Context) (string, error) {
+			return c.Preview(ctx, scriptItem.Preview.Command, scriptItem.Preview.With)
+		}
+		// The ttl is validated by the page schema
+		listItem.PreviewTTL, _ = time.ParseDuration(scriptItem.Preview.Ttl)
+	}
+
+	return listItem
 }
 
 func (c *CommandRunner) SetIsloading(isLoading bool) tea.Cmd {
@@ -238,14 +611,54 @@ func (c *CommandRunner) Update(msg tea.Msg) (Page, tea.Cmd) {
 			if c.currentView != "loading" {
 				break
 			}
+
+			// Kill the running command, its output is replaced by the cancelled state
+			if c.cancelRun != nil {
+				c.cancelRun()
+				return c, nil
+			}
 			return c, PopCmd
 		}
+	case CommandCancelledMsg:
+		if msg.run != c.runCtx {
+			return c, nil
+		}
+
+		c.cancelRun = nil
+		c.stream = nil
+		c.currentView = "detail"
+		c.detail = NewDetail(c.extension.Title)
+		kind := "Command"
+		if c.request != nil {
+			kind = "Request"
+		}
+		if msg.TimedOut {
+			c.detail.SetContent(fmt.Sprintf("%s timed out after %s", kind, c.command.Timeout))
+		} else {
+			c.detail.SetContent(fmt.Sprintf("%s cancelled", kind))
+		}
+		c.detail.SetActions(Action{
+			Title:    "Run Again",
+			Shortcut: "enter",
+			Cmd: func() tea.Msg {
+				return runAgainMsg{}
+			},
+		})
+		c.detail.SetSize(c.width, c.height)
+
+		return c, c.detail.Init()
 	case CommandOutput:
+		// The output of a superseded run would replace the one of the current run
+		if msg.run != c.runCtx {
+			return c, nil
+		}
+
+		c.cancelRun = nil
 		switch c.command.OnSuccess {
 		case "push-page":
 			var page app.Page
 			var v any
-			if err := json.Unmarshal(msg, &v); err != nil {
+			if err := json.Unmarshal(msg.Output, &v); err != nil {
 				return c, NewErrorCmd(err)
 			}
 
@@ -253,7 +666,7 @@ func (c *CommandRunner) Update(msg tea.Msg) (Page, tea.Cmd) {
 				return c, NewErrorCmd(err)
 			}
 
-			err := json.Unmarshal(msg, &page)
+			err := json.Unmarshal(msg.Output, &page)
 			if err != nil {
 				return c, NewErrorCmd(err)
 			}
@@ -268,145 +681,235 @@ func (c *CommandRunner) Update(msg tea.Msg) (Page, tea.Cmd) {
 				c.detail = NewDetail(page.Title)
 
 				actions := make([]Action, len(page.Detail.Actions))
-				for i, scriptAction := range page.Detail.Actions {
+				for i, scriptAction := range c.resolvePaths(page.Detail.Actions) {
 					actions[i] = NewAction(scriptAction)
 				}
+
+				// Links can not be clicked, so they are opened through actions
+				for _, item := range page.Detail.Metadata {
+					if item.Url == "" {
+						continue
+					}
+					actions = append(actions, Action{
+						Title: fmt.Sprintf("Open %s", item.Label),
+						Cmd:   NewOpenUrlCmd(item.Url),
+					})
+				}
 				c.detail.SetActions(actions...)
+				c.detail.Metadata = page.Detail.Metadata
 
+				c.detail.Format = page.Detail.Preview.Format
+				c.detail.Language = page.Detail.Preview.Language
 				if page.Detail.Preview.Text != "" {
-					c.detail.viewport.SetContent(page.Detail.Preview.Text)
+					c.detail.SetContent(page.Detail.Preview.Text)
 				}
 
 				if page.Detail.Preview.Command != "" {
 					c.detail.PreviewCommand = func() string {
-						command, ok := c.extension.Commands[page.Detail.Preview.Command]
-						if !ok {
-							return ""
-						}
-
-						params := app.CommandParams{
-							With: page.Detail.Preview.With,
-						}
-
-						cmd, err := command.Cmd(params, c.extension.Root.Path)
+						content, err := c.Preview(c.ctx, page.Detail.Preview.Command, page.Detail.Preview.With)
 						if err != nil {
 							return err.Error()
 						}
-
-						output, err := cmd.Output()
-						if err != nil {
-							var exitErr *exec.ExitError
-							if ok := errors.As(err, &exitErr); ok {
-								return fmt.Sprintf("command failed with exit code %d, error:\n%s", exitErr.ExitCode(), exitErr.Stderr)
-							}
-							return err.Error()
-						}
-
-						return string(output)
+						return content
 					}
 				}
 				c.detail.SetSize(c.width, c.height)
 
 				return c, c.detail.Init()
-			case "list":
-				c.currentView = "list"
-				listItems := make([]ListItem, len(page.List.Items))
-				for i, scriptItem := range page.List.Items {
-					scriptItem := scriptItem
-
-					if scriptItem.Id == "" {
-						scriptItem.Id = strconv.Itoa(i)
+			case "form":
+				formItems := make([]FormItem, len(page.Form.Fields))
+				for i, field := range page.Form.Fields {
+					if field.Title == "" {
+						field.Title = field.Name
 					}
+					formItems[i] = NewFormItem(field.Name, field.FormItem)
+				}
 
-					listItem := ParseScriptItem(scriptItem)
-					if scriptItem.Preview.Command != "" {
-						listItem.PreviewCmd = func() string {
-							command, ok := c.extension.Commands[scriptItem.Preview.Command]
-							if !ok {
-								return fmt.Sprintf("command %s not found", scriptItem.Preview.Command)
-							}
-
-							params := app.CommandParams{
-								With: scriptItem.Preview.With,
-							}
-							if c.extension.Root.Scheme != "file" {
-								payload, err := json.Marshal(params)
-								if err != nil {
-									return fmt.Sprintf("failed to marshal command params: %v", err)
-								}
-
-								commandUrl := url.URL{
-									Scheme: c.extension.Root.Scheme,
-									Host:   c.extension.Root.Host,
-									Path:   path.Join(c.extension.Root.Path, scriptItem.Preview.Command),
-								}
-								res, err := http.Post(commandUrl.String(), "application/json", bytes.NewReader(payload))
-								if err != nil {
-									return fmt.Sprintf("failed to execute command: %v", err)
-								}
-								defer res.Body.Close()
-
-								if res.StatusCode != http.StatusOK {
-									return fmt.Sprintf("failed to execute command: %s", res.Status)
-								}
-
-								body, err := io.ReadAll(res.Body)
-								if err != nil {
-									return fmt.Sprintf("failed to read command output: %v", err)
-								}
-
-								return string(body)
-							}
-
-							cmd, err := command.Cmd(params, c.extension.Root.Path)
-							if err != nil {
-								return err.Error()
-							}
-
-							output, err := cmd.Output()
-							if err != nil {
-								var exitErr *exec.ExitError
-								if ok := errors.As(err, &exitErr); ok {
-									return fmt.Sprintf("command failed with exit code %d, error:\n%s", exitErr.ExitCode(), exitErr.Stderr)
-								}
-								return err.Error()
-							}
-
-							return string(output)
-						}
+				c.currentView = "form"
+				c.submit = &page.Form.Submit
+				c.form = NewForm(page.Title, formItems)
+				c.form.SetSize(c.width, c.height)
+
+				return c, c.form.Init()
+			case "list":
+				listItems := make([]ListItem, 0, len(page.List.Items))
+				for _, scriptItem := range page.List.Items {
+					listItems = append(listItems, c.newListItem(len(listItems), scriptItem))
+				}
+				for _, section := range page.List.Sections {
+					for _, scriptItem := range section.Items {
+						listItem := c.newListItem(len(listItems), scriptItem)
+						listItem.Section = section.Title
+						listItems = append(listItems, listItem)
 					}
+				}
 
-					listItems[i] = listItem
+				// Generator lists are reloaded when the query changes, the list is kept to preserve the query
+				if c.isGenerator() {
+					return c, tea.Batch(c.list.SetItems(listItems), c.list.SetIsLoading(false))
 				}
 
+				c.currentView = "list"
 				c.list = NewList(page.Title)
 				c.list.filter.emptyText = page.List.EmptyText
+				c.list.IsGenerator = page.List.Generator
+				c.list.MultiSelect = page.List.MultiSelect
+				if page.List.MatchMode != "" {
+					c.list.filter.MatchMode = page.List.MatchMode
+				}
+				c.list.ctx = c.ctx
+				c.list.cacheKey = c.pageKey()
 				if page.List.ShowPreview {
 					c.list.ShowPreview = true
 				}
 
+				if err := c.list.SetPreviewLayout(page.List.PreviewLayout); err != nil {
+					return c, NewErrorCmd(err)
+				}
+
 				c.list.SetItems(listItems)
 				c.list.SetSize(c.width, c.height)
 
 				return c, c.list.Init()
 			}
 		case "open-url":
-			return c, NewOpenUrlCmd(string(msg))
+			if c.command.ShouldExit() {
+				return c, NewOpenUrlCmd(string(msg.Output))
+			}
+			return c, tea.Sequence(PopCmd, func() tea.Msg {
+				return OpenUrlMsg{Url: string(msg.Output)}
+			})
 		case "copy-text":
-			return c, NewCopyTextCmd(string(msg))
+			if c.command.ShouldExit() {
+				return c, NewCopyTextCmd(string(msg.Output))
+			}
+			return c, tea.Sequence(PopCmd, func() tea.Msg {
+				return CopyTextMsg{Text: string(msg.Output)}
+			})
 		case "reload-page":
 			return c, tea.Sequence(PopCmd, NewReloadPageCmd(nil))
+		case "show-toast":
+			toast, err := ParseToast(msg.Output)
+			if err != nil {
+				return c, NewErrorCmd(err)
+			}
+
+			return c, tea.Sequence(PopCmd, func() tea.Msg {
+				return ShowToastMsg{Toast: toast}
+			})
 		default:
-			return c, tea.Quit
+			if c.command.ShouldExit() {
+				return c, tea.Quit
+			}
+			if c.request != nil {
+				return c, tea.Sequence(PopCmd, NewToastCmd(ToastSuccess, "Request succeeded"))
+			}
+			return c, tea.Sequence(PopCmd, NewToastCmd(ToastSuccess, "Command succeeded"))
 		}
 
+	case ListStreamMsg:
+		if msg.run != c.runCtx {
+			return c, nil
+		}
+
+		if msg.Page.Title == "" {
+			msg.Page.Title = c.extension.Title
+		}
+
+		c.stream = msg.Stream
+		c.streamedItems = make([]ListItem, 0)
+
+		// The items of generator lists are replaced when the first batch of the new run arrives
+		if c.isGenerator() {
+			return c, tea.Batch(c.list.SetIsLoading(true), c.stream.Next)
+		}
+
+		c.currentView = "list"
+		c.list = NewList(msg.Page.Title)
+		c.list.filter.emptyText = msg.Page.List.EmptyText
+		c.list.IsGenerator = msg.Page.List.Generator
+		c.list.MultiSelect = msg.Page.List.MultiSelect
+		if msg.Page.List.MatchMode != "" {
+			c.list.filter.MatchMode = msg.Page.List.MatchMode
+		}
+		c.list.ctx = c.ctx
+		c.list.cacheKey = c.pageKey()
+		c.list.ShowPreview = msg.Page.List.ShowPreview
+		if err := c.list.SetPreviewLayout(msg.Page.List.PreviewLayout); err != nil {
+			return c, NewErrorCmd(err)
+		}
+		c.list.SetSize(c.width, c.height)
+
+		return c, tea.Batch(c.list.Init(), c.list.SetIsLoading(true), c.stream.Next)
+
+	case ListStreamItemsMsg:
+		// Ignore the items of a previous run
+		if msg.Stream != c.stream {
+			return c, nil
+		}
+
+		for _, scriptItem := range msg.Items {
+			c.streamedItems = append(c.streamedItems, c.newListItem(len(c.streamedItems), scriptItem))
+		}
+		cmd := c.list.SetItems(c.streamedItems)
+
+		if !msg.Done {
+			return c, tea.Batch(cmd, c.stream.Next)
+		}
+
+		c.stream = nil
+		if errors.Is(msg.Err, context.Canceled) || errors.Is(msg.Err, context.DeadlineExceeded) {
+			runCtx := c.runCtx
+			return c, tea.Batch(cmd, func() tea.Msg {
+				return c.cancelledMsg(runCtx)
+			})
+		}
+		c.cancelRun = nil
+		if msg.Err != nil {
+			return c, NewErrorCmd(errors.New(formatCommandError(msg.Err)))
+		}
+
+		return c, tea.Batch(cmd, c.list.SetIsLoading(false))
+
 	case SubmitFormMsg:
+		if c.submit != nil {
+			with := make(map[string]app.CommandInput)
+			for name, input := range c.submit.With {
+				with[name] = input
+			}
+			for name, value := range msg.Values {
+				with[name] = app.CommandInput{Value: value}
+			}
+
+			submit := *c.submit
+			return c, func() tea.Msg {
+				return RunCommandMsg{
+					Command:   submit.Command,
+					With:      with,
+					OnSuccess: submit.OnSuccess,
+				}
+			}
+		}
+
+		preferences := make([]ScriptPreference, 0)
 		for key, value := range msg.Values {
+			if preference, ok := c.missingPreferences[key]; ok {
+				preference.Value = value
+				preferences = append(preferences, preference)
+				continue
+			}
+
 			c.with[key] = app.CommandInput{
 				Value: value,
 			}
 		}
 
+		if len(preferences) > 0 {
+			if err := keyStore.SetPreference(preferences...); err != nil {
+				return c, NewErrorCmd(err)
+			}
+		}
+
 		c.currentView = "loading"
 
 		return c, tea.Sequence(c.SetIsloading(true), c.Run())
@@ -419,15 +922,43 @@ func (c *CommandRunner) Update(msg tea.Msg) (Page, tea.Cmd) {
 		if msg.OnSuccess != "" {
 			command.OnSuccess = msg.OnSuccess
 		}
+		if msg.Exit != nil {
+			command.Exit = msg.Exit
+		}
 
-		c.Clear()
-
-		return c, NewPushCmd(NewCommandRunner(c.extension, NamedCommand{
+		runner := NewCommandRunner(c.extension, NamedCommand{
 			Name:    msg.Command,
 			Command: command,
-		}, msg.With))
+		}, msg.With)
+
+		if msg.Batch && c.currentView == "list" {
+			input, err := c.list.BatchInput()
+			if err != nil {
+				return c, NewErrorCmd(err)
+			}
+			runner.input = input
+			c.list.ClearChecked()
+		}
+
+		c.Clear()
+
+		return c, NewPushCmd(runner)
+
+	case SendRequestMsg:
+		c.Clear()
+
+		return c, NewPushCmd(NewRequestRunner(c.extension, msg.Action))
+
+	case runAgainMsg:
+		c.currentView = "loading"
+		return c, tea.Sequence(c.SetIsloading(true), c.Run())
 
 	case ReloadPageMsg:
+		// Generators are reloaded each time the query changes, their previews are kept
+		if !c.isGenerator() {
+			previewCache.DeletePrefix(c.pageKey() + "/")
+		}
+
 		for key, value := range msg.With {
 			c.with[key] = value
 		}
@@ -0,0 +1,60 @@
+package tui
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/pomdtr/sunbeam/app"
+)
+
+func TestSupersededRun(t *testing.T) {
+	extension := NamedExtension{Name: "jira", Extension: app.Extension{Root: &url.URL{Scheme: "file", Path: "/extensions/jira"}}}
+	runner := NewCommandRunner(extension, NamedCommand{
+		Name:    "search-issues",
+		Command: app.Command{OnSuccess: "push-page"},
+	}, nil)
+	runner.SetSize(80, 20)
+
+	page := func(title string) []byte {
+		return []byte(`{"type": "list", "generator": true, "items": [{"title": "` + title + `", "actions": []}]}`)
+	}
+	titles := func() []string {
+		titles := make([]string, 0)
+		for _, item := range runner.list.filter.items {
+			titles = append(titles, item.(ListItem).Title)
+		}
+		return titles
+	}
+
+	firstRun, _ := runner.runContext()
+	runner.Update(CommandOutput{Output: page("SUN-1"), run: firstRun})
+	if got := titles(); len(got) != 1 || got[0] != "SUN-1" {
+		t.Fatalf("got %v, want the items of the first run", got)
+	}
+
+	// The query changed, the first run finished before being cancelled by the second one
+	staleRun, _ := runner.runContext()
+	currentRun, _ := runner.runContext()
+
+	runner.Update(CommandOutput{Output: page("SUN-2"), run: staleRun})
+	if got := titles(); len(got) != 1 || got[0] != "SUN-1" {
+		t.Errorf("the output of a superseded run replaced the items: %v", got)
+	}
+	if runner.cancelRun == nil {
+		t.Errorf("the current run can not be cancelled anymore")
+	}
+
+	stream := &ListStream{items: make(chan app.ListItem)}
+	runner.Update(ListStreamMsg{Page: app.Page{Type: "list"}, Stream: stream, run: staleRun})
+	if runner.stream != nil {
+		t.Errorf("the stream of a superseded run replaced the current one")
+	}
+
+	runner.Update(CommandOutput{Output: page("SUN-3"), run: currentRun})
+	if got := titles(); len(got) != 1 || got[0] != "SUN-3" {
+		t.Errorf("got %v, want the items of the current run", got)
+	}
+	if runner.cancelRun != nil {
+		t.Errorf("the current run is done, but it is still marked as running")
+	}
+}
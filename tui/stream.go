@@ -0,0 +1,169 @@
+package tui
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pomdtr/sunbeam/app"
+)
+
+// Items are sent to the list in batches, to avoid filtering the whole list for each line of output
+const streamBatchDuration = 100 * time.Millisecond
+
+// ListStream reads the items of a streaming list page, one JSON object per line.
+type ListStream struct {
+	items chan app.ListItem
+	// err is set before items is closed
+	err error
+}
+
+type ListStreamMsg struct {
+	Page   app.Page
+	Stream *ListStream
+	run    context.Context
+}
+
+type ListStreamItemsMsg struct {
+	Stream *ListStream
+	Items  []app.ListItem
+	Done   bool
+	Err    error
+}
+
+// NewListStream starts reading items from the output of the command.
+// The command is killed through cancel if an item is invalid.
+func NewListStream(ctx context.Context, cancel context.CancelFunc, output io.Reader, wait func() error) *ListStream {
+	stream := &ListStream{
+		items: make(chan app.ListItem, 256),
+	}
+
+	go func() {
+		defer close(stream.items)
+
+		var readErr error
+		scanner := bufio.NewScanner(output)
+		scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+
+			item, err := parseStreamItem(line)
+			if err != nil {
+				readErr = err
+				cancel()
+				// Drain the output, so that the command can exit
+				io.Copy(io.Discard, output)
+				break
+			}
+
+			select {
+			case stream.items <- item:
+			case <-ctx.Done():
+			}
+		}
+
+		err := wait()
+		cancel()
+		if readErr != nil {
+			stream.err = readErr
+		} else {
+			stream.err = err
+		}
+	}()
+
+	return stream
+}
+
+// parseStreamHeader reports whether the line is the header of a streaming list, and validates it against the page schema.
+func parseStreamHeader(line []byte) (app.Page, bool, error) {
+	var header app.Page
+	if err := json.Unmarshal(line, &header); err != nil || header.Type != "list" || !header.Streaming {
+		return app.Page{}, false, nil
+	}
+
+	var v any
+	json.Unmarshal(line, &v)
+	if err := app.PageSchema.Validate(v); err != nil {
+		return app.Page{}, true, err
+	}
+
+	return header, true, nil
+}
+
+// ParseListStream parses the whole output of a streaming list, and merges its items into the header to return a single list page.
+// ok is false if the output is not a streaming list.
+func ParseListStream(output []byte) (page map[string]any, ok bool, err error) {
+	lines := bytes.Split(output, []byte("\n"))
+	if _, ok, err := parseStreamHeader(lines[0]); !ok || err != nil {
+		return nil, ok, err
+	}
+
+	if err := json.Unmarshal(lines[0], &page); err != nil {
+		return nil, true, err
+	}
+	delete(page, "streaming")
+
+	items := make([]json.RawMessage, 0, len(lines)-1)
+	for _, line := range lines[1:] {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+
+		if _, err := parseStreamItem(line); err != nil {
+			return nil, true, err
+		}
+		items = append(items, line)
+	}
+	page["items"] = items
+
+	return page, true, nil
+}
+
+func parseStreamItem(line []byte) (app.ListItem, error) {
+	var v any
+	if err := json.Unmarshal(line, &v); err != nil {
+		return app.ListItem{}, fmt.Errorf("invalid list item %s: %w", line, err)
+	}
+
+	if err := app.ListItemSchema.Validate(v); err != nil {
+		return app.ListItem{}, fmt.Errorf("invalid list item %s: %w", line, err)
+	}
+
+	var item app.ListItem
+	if err := json.Unmarshal(line, &item); err != nil {
+		return app.ListItem{}, fmt.Errorf("invalid list item %s: %w", line, err)
+	}
+
+	return item, nil
+}
+
+// Next waits for the next items of the stream, and returns them in a single message.
+func (s *ListStream) Next() tea.Msg {
+	item, ok := <-s.items
+	if !ok {
+		return ListStreamItemsMsg{Stream: s, Done: true, Err: s.err}
+	}
+
+	items := []app.ListItem{item}
+	timeout := time.After(streamBatchDuration)
+	for {
+		select {
+		case item, ok := <-s.items:
+			if !ok {
+				return ListStreamItemsMsg{Stream: s, Items: items, Done: true, Err: s.err}
+			}
+			items = append(items, item)
+		case <-timeout:
+			return ListStreamItemsMsg{Stream: s, Items: items}
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package tui
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseStreamItem(t *testing.T) {
+	item, err := parseStreamItem([]byte(`{"title": "Item 1", "subtitle": "First", "actions": []}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Title != "Item 1" || item.Subtitle != "First" {
+		t.Errorf("got %+v", item)
+	}
+
+	for _, line := range []string{`{"title": "Item 1", "actions": []`, `{"subtitle": "missing title", "actions": []}`, `"Item 1"`} {
+		if _, err := parseStreamItem([]byte(line)); err == nil {
+			t.Errorf("%s should be rejected", line)
+		}
+	}
+}
+
+func TestListStream(t *testing.T) {
+	readAll := func(stream *ListStream) ([]string, error) {
+		var titles []string
+		for {
+			msg := stream.Next().(ListStreamItemsMsg)
+			for _, item := range msg.Items {
+				titles = append(titles, item.Title)
+			}
+			if msg.Done {
+				return titles, msg.Err
+			}
+		}
+	}
+
+	t.Run("items", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		output := strings.NewReader("{\"title\": \"Item 1\", \"actions\": []}\n\n{\"title\": \"Item 2\", \"actions\": []}\n")
+		titles, err := readAll(NewListStream(ctx, cancel, output, func() error { return nil }))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if want := []string{"Item 1", "Item 2"}; !reflect.DeepEqual(titles, want) {
+			t.Errorf("got %v, want %v", titles, want)
+		}
+	})
+
+	t.Run("invalid item", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		output := strings.NewReader("{\"title\": \"Item 1\", \"actions\": []}\nnot json\n{\"title\": \"Item 3\", \"actions\": []}\n")
+		titles, err := readAll(NewListStream(ctx, cancel, output, func() error { return nil }))
+		if err == nil || !strings.Contains(err.Error(), "not json") {
+			t.Errorf("got %v, want an error for the invalid item", err)
+		}
+		if ctx.Err() == nil {
+			t.Errorf("the command should be cancelled")
+		}
+		if want := []string{"Item 1"}; !reflect.DeepEqual(titles, want) {
+			t.Errorf("got %v, want %v", titles, want)
+		}
+	})
+
+	t.Run("command error", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		exitErr := errors.New("exit status 1")
+		_, err := readAll(NewListStream(ctx, cancel, strings.NewReader(""), func() error { return exitErr }))
+		if err != exitErr {
+			t.Errorf("got %v, want %v", err, exitErr)
+		}
+	})
+}
+
+func TestParseListStream(t *testing.T) {
+	output := "{\"type\": \"list\", \"title\": \"Streamed\", \"streaming\": true}\n{\"title\": \"Item 1\", \"actions\": []}\n{\"title\": \"Item 2\", \"actions\": []}\n"
+	page, ok, err := ParseListStream([]byte(output))
+	if !ok || err != nil {
+		t.Fatalf("got ok=%t, err=%v", ok, err)
+	}
+
+	encoded, _ := json.Marshal(page)
+	want := `{"items":[{"title":"Item 1","actions":[]},{"title":"Item 2","actions":[]}],"title":"Streamed","type":"list"}`
+	if string(encoded) != want {
+		t.Errorf("got %s, want %s", encoded, want)
+	}
+
+	if _, ok, _ := ParseListStream([]byte(`{"type": "list", "items": []}`)); ok {
+		t.Errorf("pages without the streaming flag are not streams")
+	}
+
+	if _, _, err := ParseListStream([]byte("{\"type\": \"list\", \"streaming\": true}\n{\"subtitle\": \"missing title\", \"actions\": []}\n")); err == nil {
+		t.Errorf("invalid items should be rejected")
+	}
+}
@@ -8,6 +8,8 @@ type Styles struct {
 	Bold   lipgloss.Style
 	Faint  lipgloss.Style
 	Italic lipgloss.Style
+	// Match highlights the runes matching the query
+	Match lipgloss.Style
 }
 
 var styles Styles
@@ -17,5 +19,6 @@ func init() {
 		Bold:   lipgloss.NewStyle().Bold(true),
 		Faint:  lipgloss.NewStyle().Faint(true),
 		Italic: lipgloss.NewStyle().Italic(true),
+		Match:  lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("2")),
 	}
 }
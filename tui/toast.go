@@ -0,0 +1,83 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/reflow/truncate"
+	"github.com/pomdtr/sunbeam/utils"
+)
+
+// toastDuration is the time a toast is displayed before being hidden
+const toastDuration = 2 * time.Second
+
+const (
+	ToastSuccess = "success"
+	ToastFailure = "failure"
+)
+
+// Toast is a transient message displayed over the footer of the current page.
+type Toast struct {
+	Title string `json:"title"`
+	Style string `json:"style"`
+}
+
+type ShowToastMsg struct {
+	Toast Toast
+}
+
+type hideToastMsg struct {
+	id int
+}
+
+func NewToastCmd(style string, title string) tea.Cmd {
+	return func() tea.Msg {
+		return ShowToastMsg{
+			Toast: Toast{
+				Title: title,
+				Style: style,
+			},
+		}
+	}
+}
+
+// ParseToast parses the output of a command, either a plain text title or a json object with a title and a style.
+func ParseToast(output []byte) (Toast, error) {
+	text := strings.TrimSpace(string(output))
+	if !strings.HasPrefix(text, "{") {
+		return Toast{Title: text, Style: ToastSuccess}, nil
+	}
+
+	var toast Toast
+	if err := json.Unmarshal([]byte(text), &toast); err != nil {
+		return Toast{}, fmt.Errorf("invalid toast: %s", err)
+	}
+
+	switch toast.Style {
+	case "":
+		toast.Style = ToastSuccess
+	case ToastSuccess, ToastFailure:
+	default:
+		return Toast{}, fmt.Errorf("invalid toast style: %s, expected %s or %s", toast.Style, ToastSuccess, ToastFailure)
+	}
+
+	return toast, nil
+}
+
+// View renders the toast on a single line, the title is truncated if it does not fit.
+func (t Toast) View(width int) string {
+	icon, color := "✓", lipgloss.Color("2")
+	if t.Style == ToastFailure {
+		icon, color = "✗", lipgloss.Color("1")
+	}
+
+	// Multiline titles would break the layout of the page
+	title := strings.Join(strings.Fields(t.Title), " ")
+	text := truncate.StringWithTail(fmt.Sprintf("%s %s", icon, title), uint(utils.Max(0, width-2)), "…")
+
+	return lipgloss.NewStyle().Bold(true).Foreground(color).Padding(0, 1).Width(width).Render(text)
+}
@@ -0,0 +1,25 @@
+package tui
+
+import "testing"
+
+func TestParseToast(t *testing.T) {
+	cases := map[string]Toast{
+		"3 issues closed\n": {Title: "3 issues closed", Style: ToastSuccess},
+		`{"title": "1 issue could not be closed", "style": "failure"}`: {Title: "1 issue could not be closed", Style: ToastFailure},
+		`{"title": "Done"}`: {Title: "Done", Style: ToastSuccess},
+	}
+
+	for output, want := range cases {
+		got, err := ParseToast([]byte(output))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("%q: got %+v, want %+v", output, got, want)
+		}
+	}
+
+	if _, err := ParseToast([]byte(`{"title": "Done", "style": "warning"}`)); err == nil {
+		t.Errorf("invalid style should be rejected")
+	}
+}
@@ -0,0 +1,265 @@
+package utils
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var archiveExtensions = []string{".tar.gz", ".tgz", ".zip"}
+
+// archiveClient downloads remote archives, the timeout includes the time spent reading the body.
+var archiveClient = &http.Client{Timeout: 5 * time.Minute}
+
+func archiveExtension(source string) string {
+	if u, err := url.Parse(source); err == nil && u.Scheme != "" {
+		source = u.Path
+	}
+
+	for _, extension := range archiveExtensions {
+		if strings.HasSuffix(strings.ToLower(source), extension) {
+			return extension
+		}
+	}
+
+	return ""
+}
+
+// IsArchive reports whether source points to a .tar.gz, .tgz or .zip archive.
+func IsArchive(source string) bool {
+	return archiveExtension(source) != ""
+}
+
+// DownloadArchive copies the archive at source, which can be a local path or a file:// or http(s):// url, to a temporary file.
+// It returns the path of the temporary file and the sha256 checksum of its content.
+func DownloadArchive(source string) (archivePath string, checksum string, err error) {
+	var reader io.ReadCloser
+	u, err := url.Parse(source)
+	switch {
+	case err == nil && (u.Scheme == "http" || u.Scheme == "https"):
+		res, err := archiveClient.Get(source)
+		if err != nil {
+			return "", "", err
+		}
+		if res.StatusCode != http.StatusOK {
+			res.Body.Close()
+			return "", "", fmt.Errorf("failed to download %s: %s", source, res.Status)
+		}
+		reader = res.Body
+	case err == nil && u.Scheme == "file":
+		if reader, err = os.Open(u.Path); err != nil {
+			return "", "", err
+		}
+	default:
+		if reader, err = os.Open(source); err != nil {
+			return "", "", err
+		}
+	}
+	defer reader.Close()
+
+	f, err := os.CreateTemp(os.TempDir(), "sunbeam-*"+archiveExtension(source))
+	if err != nil {
+		return "", "", err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(f, hash), reader); err != nil {
+		os.Remove(f.Name())
+		return "", "", err
+	}
+
+	return f.Name(), hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// ExtractArchive extracts the archive at archivePath into the target directory.
+func ExtractArchive(archivePath string, target string) error {
+	switch archiveExtension(archivePath) {
+	case ".tar.gz", ".tgz":
+		return extractTarGz(archivePath, target)
+	case ".zip":
+		return extractZip(archivePath, target)
+	default:
+		return fmt.Errorf("unsupported archive format: %s", archivePath)
+	}
+}
+
+func isOutside(root string, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// archiveEntryPath returns the path an archive entry should be extracted to, making sure it stays inside the target directory.
+// Entries can not be extracted through the symlinks of the archive, since they could point anywhere once combined.
+func archiveEntryPath(target string, name string) (string, error) {
+	entryPath := filepath.Join(target, filepath.FromSlash(name))
+	if isOutside(target, entryPath) {
+		return "", fmt.Errorf("archive entry %s is outside of the target directory", name)
+	}
+
+	rel, _ := filepath.Rel(target, entryPath)
+	current := target
+	for _, component := range strings.Split(rel, string(filepath.Separator)) {
+		current = filepath.Join(current, component)
+		fi, err := os.Lstat(current)
+		if os.IsNotExist(err) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		if fi.Mode()&os.ModeSymlink != 0 {
+			return "", fmt.Errorf("archive entry %s is extracted through a symlink", name)
+		}
+	}
+
+	return entryPath, nil
+}
+
+// extractSymlink creates the symlink of an archive entry, its destination must exist and be inside the target directory.
+func extractSymlink(target string, header *tar.Header) error {
+	entryPath, err := archiveEntryPath(target, header.Name)
+	if err != nil {
+		return err
+	}
+
+	if filepath.IsAbs(header.Linkname) {
+		return fmt.Errorf("archive entry %s links to an absolute path", header.Name)
+	}
+
+	// The destination is resolved on disk, since it can go through the other symlinks of the archive
+	root, err := filepath.EvalSymlinks(target)
+	if err != nil {
+		return err
+	}
+	destination, err := filepath.EvalSymlinks(filepath.Join(filepath.Dir(entryPath), header.Linkname))
+	if err != nil {
+		return fmt.Errorf("archive entry %s links to a missing file: %s", header.Name, header.Linkname)
+	}
+	if isOutside(root, destination) {
+		return fmt.Errorf("archive entry %s links outside of the target directory", header.Name)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(entryPath), 0755); err != nil {
+		return err
+	}
+
+	return os.Symlink(header.Linkname, entryPath)
+}
+
+func writeArchiveFile(entryPath string, reader io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(entryPath), 0755); err != nil {
+		return err
+	}
+
+	if mode.Perm() == 0 {
+		mode = 0644
+	}
+
+	f, err := os.OpenFile(entryPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm())
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(f, reader)
+	return err
+}
+
+func extractTarGz(archivePath string, target string) error {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("failed to read archive: %w", err)
+	}
+	defer gz.Close()
+
+	// Symlinks are created last, so that they can point to any file of the archive
+	var symlinks []*tar.Header
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		entryPath, err := archiveEntryPath(target, header.Name)
+		if err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(entryPath, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := writeArchiveFile(entryPath, tr, header.FileInfo().Mode()); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			symlinks = append(symlinks, header)
+		}
+	}
+
+	for _, header := range symlinks {
+		if err := extractSymlink(target, header); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func extractZip(archivePath string, target string) error {
+	zr, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to read archive: %w", err)
+	}
+	defer zr.Close()
+
+	for _, file := range zr.File {
+		entryPath, err := archiveEntryPath(target, file.Name)
+		if err != nil {
+			return err
+		}
+
+		if file.FileInfo().IsDir() {
+			if err := os.MkdirAll(entryPath, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+
+		reader, err := file.Open()
+		if err != nil {
+			return err
+		}
+
+		err = writeArchiveFile(entryPath, reader, file.Mode())
+		reader.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package utils
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+)
+
+func writeTarGz(t *testing.T, archivePath string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	defer gz.Close()
+	tw := tar.NewWriter(gz)
+	defer tw.Close()
+
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// writeTarGzHeaders writes entries without content in order, to create archives with symlinks.
+func writeTarGzHeaders(t *testing.T, archivePath string, headers []*tar.Header) {
+	t.Helper()
+	f, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	defer gz.Close()
+	tw := tar.NewWriter(gz)
+	defer tw.Close()
+
+	for _, header := range headers {
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestExtractArchive(t *testing.T) {
+	archivePath := path.Join(t.TempDir(), "extension.tar.gz")
+	writeTarGz(t, archivePath, map[string]string{
+		"extension/sunbeam.yml": "title: Extension",
+		"extension/script.sh":   "echo hello",
+	})
+
+	target := t.TempDir()
+	if err := ExtractArchive(archivePath, target); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(path.Join(target, "extension", "sunbeam.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "title: Extension" {
+		t.Errorf("got %q, want %q", content, "title: Extension")
+	}
+
+	fi, err := os.Stat(path.Join(target, "extension", "script.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm()&0100 == 0 {
+		t.Errorf("expected script.sh to be executable, got mode %s", fi.Mode())
+	}
+}
+
+func TestExtractArchiveOutsideTarget(t *testing.T) {
+	archivePath := path.Join(t.TempDir(), "extension.tgz")
+	writeTarGz(t, archivePath, map[string]string{
+		"../escaped": "boom",
+	})
+
+	if err := ExtractArchive(archivePath, t.TempDir()); err == nil {
+		t.Error("expected an error when extracting an entry outside of the target directory")
+	}
+}
+
+func TestExtractArchiveSymlinks(t *testing.T) {
+	archivePath := path.Join(t.TempDir(), "extension.tgz")
+	writeTarGzHeaders(t, archivePath, []*tar.Header{
+		{Name: "extension/bin/run", Linkname: "../script.sh", Typeflag: tar.TypeSymlink},
+		{Name: "extension/script.sh", Mode: 0755, Typeflag: tar.TypeReg},
+	})
+
+	target := t.TempDir()
+	if err := ExtractArchive(archivePath, target); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(path.Join(target, "extension", "bin", "run")); err != nil {
+		t.Errorf("symlink to a file of the archive was not extracted: %s", err)
+	}
+}
+
+func TestExtractArchiveSymlinksOutsideTarget(t *testing.T) {
+	cases := map[string][]*tar.Header{
+		"link outside": {
+			{Name: "escaped", Linkname: "..", Typeflag: tar.TypeSymlink},
+		},
+		"chained links": {
+			{Name: "a", Linkname: ".", Typeflag: tar.TypeSymlink},
+			{Name: "b", Linkname: "a/..", Typeflag: tar.TypeSymlink},
+			{Name: "b/escaped", Mode: 0644, Typeflag: tar.TypeReg},
+		},
+		"write through link": {
+			{Name: "a", Linkname: ".", Typeflag: tar.TypeSymlink},
+			{Name: "a/escaped", Mode: 0644, Typeflag: tar.TypeReg},
+		},
+	}
+
+	for key, headers := range cases {
+		headers := headers
+		t.Run(key, func(t *testing.T) {
+			archivePath := path.Join(t.TempDir(), "extension.tgz")
+			writeTarGzHeaders(t, archivePath, headers)
+
+			parent := t.TempDir()
+			target := filepath.Join(parent, "target")
+			if err := os.Mkdir(target, 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := ExtractArchive(archivePath, target); err == nil {
+				t.Error("expected an error when extracting a symlink outside of the target directory")
+			}
+			if _, err := os.Stat(filepath.Join(parent, "escaped")); err == nil {
+				t.Error("a file was written outside of the target directory")
+			}
+		})
+	}
+}
@@ -2,21 +2,57 @@ package utils
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 )
 
 type GitClient struct {
-	repo string
+	repo   string
+	remote string
 }
 
 func NewGitClient(repo string) *GitClient {
 	return &GitClient{
-		repo: repo,
+		repo:   repo,
+		remote: "origin",
 	}
 }
 
+// NewRemoteGitClient returns a client querying the repository at url, without a local clone.
+func NewRemoteGitClient(url string) *GitClient {
+	return &GitClient{
+		remote: url,
+	}
+}
+
+// GitSource is a git repository url, optionally pointing to a subdirectory and a ref.
+type GitSource struct {
+	Url  string
+	Path string
+	Ref  string
+}
+
+// ParseGitSource parses sources of the form url#path/to/dir@ref, where both the path and the ref are optional.
+func ParseGitSource(source string) GitSource {
+	url, fragment, ok := strings.Cut(source, "#")
+	if !ok {
+		return GitSource{Url: source}
+	}
+
+	gitSource := GitSource{Url: url, Path: fragment}
+	if idx := strings.LastIndex(fragment, "@"); idx != -1 {
+		gitSource.Path = fragment[:idx]
+		gitSource.Ref = fragment[idx+1:]
+	}
+	gitSource.Path = strings.Trim(gitSource.Path, "/")
+
+	return gitSource
+}
+
 func GitClone(url string, target string) error {
 	cmd := exec.Command("git", "clone", "--filter=blob:none", url, target)
 	cmd.Stderr = os.Stderr
@@ -42,6 +78,9 @@ func (gc *GitClient) Config(name string) (string, error) {
 }
 
 func (gc *GitClient) GetOrigin() string {
+	if gc.repo == "" {
+		return gc.remote
+	}
 	origin, _ := gc.Config("remote.origin.url")
 	return strings.TrimSpace(origin)
 }
@@ -59,12 +98,239 @@ func (gc *GitClient) GetCurrentVersion() string {
 	return strings.TrimSpace(string(res))
 }
 
-func (gc *GitClient) GetLatestVersion() (string, error) {
-	cmd := exec.Command("git", "ls-remote", "origin", "HEAD")
+// GetCurrentTag returns the semver tag pointing at HEAD, if any.
+func (gc *GitClient) GetCurrentTag() string {
+	cmd := exec.Command("git", "tag", "--points-at", "HEAD")
+	cmd.Dir = gc.repo
+	res, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+
+	return highestSemverTag(strings.Fields(string(res)))
+}
+
+// lsRemote lists the refs of the origin remote matching the given patterns.
+// Annotated tags are resolved to the commit they point to.
+func (gc *GitClient) lsRemote(flags []string, patterns ...string) (map[string]string, error) {
+	args := append([]string{"ls-remote"}, flags...)
+	args = append(args, gc.remote)
+	cmd := exec.Command("git", append(args, patterns...)...)
+	cmd.Dir = gc.repo
 	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("git ls-remote failed: %s", bytes.TrimSpace(exitErr.Stderr))
+		}
+		return nil, err
+	}
+
+	refs := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		sha, ref, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+
+		if peeled := strings.TrimSuffix(ref, "^{}"); peeled != ref {
+			refs[peeled] = sha
+			continue
+		}
+
+		if _, ok := refs[ref]; !ok {
+			refs[ref] = sha
+		}
+	}
+
+	return refs, nil
+}
+
+// GetDefaultBranch returns the name of the branch the origin HEAD points to.
+func (gc *GitClient) GetDefaultBranch() (string, error) {
+	cmd := exec.Command("git", "ls-remote", "--symref", gc.remote, "HEAD")
+	cmd.Dir = gc.repo
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+
+	for _, line := range strings.Split(string(output), "\n") {
+		if !strings.HasPrefix(line, "ref: ") {
+			continue
+		}
+		ref, _, _ := strings.Cut(strings.TrimPrefix(line, "ref: "), "\t")
+		return strings.TrimPrefix(ref, "refs/heads/"), nil
+	}
+
+	return "", fmt.Errorf("could not determine the default branch of %s", gc.GetOrigin())
+}
+
+// GetLatestTag returns the highest semver tag of the origin remote, or an empty string if there is none.
+func (gc *GitClient) GetLatestTag() (string, error) {
+	refs, err := gc.lsRemote([]string{"--tags"})
+	if err != nil {
+		return "", err
+	}
+
+	tags := make([]string, 0, len(refs))
+	for ref := range refs {
+		tags = append(tags, strings.TrimPrefix(ref, "refs/tags/"))
+	}
+
+	return highestSemverTag(tags), nil
+}
+
+// GetCurrentBranch returns the name of the branch checked out in the repository, or an empty string if HEAD is detached.
+func (gc *GitClient) GetCurrentBranch() string {
+	cmd := exec.Command("git", "symbolic-ref", "--quiet", "--short", "HEAD")
+	cmd.Dir = gc.repo
+	res, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(res))
+}
+
+// GetLatestRef returns the ref an extension should be upgraded to.
+// Extensions checked out on a branch follow this branch, even if HEAD is also tagged.
+// Extensions checked out at a semver tag on a detached HEAD follow the highest tag, and others follow the default branch.
+func (gc *GitClient) GetLatestRef() (string, error) {
+	if branch := gc.GetCurrentBranch(); branch != "" {
+		return branch, nil
+	}
+
+	if gc.GetCurrentTag() != "" {
+		tag, err := gc.GetLatestTag()
+		if err != nil {
+			return "", err
+		}
+		if tag != "" {
+			return tag, nil
+		}
+	}
+
+	return gc.GetDefaultBranch()
+}
+
+// ResolveRef returns the commit sha a branch or tag of the origin remote points to.
+func (gc *GitClient) ResolveRef(ref string) (string, error) {
+	refs, err := gc.lsRemote(nil, ref, ref+"^{}")
 	if err != nil {
 		return "", err
 	}
-	remoteSha := bytes.SplitN(output, []byte("\t"), 2)[0]
-	return string(remoteSha), nil
+
+	for _, candidate := range []string{ref, "refs/heads/" + ref, "refs/tags/" + ref} {
+		if sha, ok := refs[candidate]; ok {
+			return sha, nil
+		}
+	}
+
+	return "", fmt.Errorf("ref %s not found in %s", ref, gc.GetOrigin())
+}
+
+func (gc *GitClient) GetLatestVersion() (string, error) {
+	ref, err := gc.GetLatestRef()
+	if err != nil {
+		return "", err
+	}
+
+	return gc.ResolveRef(ref)
+}
+
+// Checkout fetches the given branch or tag from origin and checks it out.
+// Branches are checked out as local branches, tags as a detached HEAD.
+func (gc *GitClient) Checkout(ref string) error {
+	refs, err := gc.lsRemote([]string{"--heads"}, ref)
+	if err != nil {
+		return err
+	}
+	_, isBranch := refs["refs/heads/"+ref]
+
+	fetch := exec.Command("git", "fetch", "--quiet", "--tags", "origin", ref)
+	fetch.Dir = gc.repo
+	fetch.Stderr = os.Stderr
+	if err := fetch.Run(); err != nil {
+		return err
+	}
+
+	var checkout *exec.Cmd
+	if isBranch {
+		checkout = exec.Command("git", "checkout", "--quiet", "-B", ref, "FETCH_HEAD")
+	} else {
+		checkout = exec.Command("git", "checkout", "--quiet", "--detach", "FETCH_HEAD")
+	}
+	checkout.Dir = gc.repo
+	checkout.Stderr = os.Stderr
+	return checkout.Run()
+}
+
+// CheckoutCommit checks out a commit as a detached HEAD, fetching it from origin if it is not available locally.
+func (gc *GitClient) CheckoutCommit(sha string) error {
+	exists := exec.Command("git", "cat-file", "-e", sha+"^{commit}")
+	exists.Dir = gc.repo
+	if err := exists.Run(); err != nil {
+		fetch := exec.Command("git", "fetch", "--quiet", "origin", sha)
+		fetch.Dir = gc.repo
+		fetch.Stderr = os.Stderr
+		if err := fetch.Run(); err != nil {
+			return fmt.Errorf("failed to fetch commit %s: %w", sha, err)
+		}
+	}
+
+	checkout := exec.Command("git", "checkout", "--quiet", "--detach", sha)
+	checkout.Dir = gc.repo
+	checkout.Stderr = os.Stderr
+	return checkout.Run()
+}
+
+// IsSemverTag reports whether ref is a tag of the form vX.Y.Z or X.Y.Z.
+func IsSemverTag(ref string) bool {
+	_, ok := parseSemver(ref)
+	return ok
+}
+
+// highestSemverTag returns the highest tag of the form vX.Y.Z or X.Y.Z, ignoring pre-releases.
+func highestSemverTag(tags []string) string {
+	var latest string
+	var latestVersion [3]int
+	for _, tag := range tags {
+		version, ok := parseSemver(tag)
+		if !ok {
+			continue
+		}
+
+		if latest == "" || compareSemver(version, latestVersion) > 0 {
+			latest = tag
+			latestVersion = version
+		}
+	}
+
+	return latest
+}
+
+func parseSemver(tag string) (version [3]int, ok bool) {
+	parts := strings.Split(strings.TrimPrefix(tag, "v"), ".")
+	if len(parts) != 3 {
+		return version, false
+	}
+
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return version, false
+		}
+		version[i] = n
+	}
+
+	return version, true
+}
+
+func compareSemver(a, b [3]int) int {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] - b[i]
+		}
+	}
+	return 0
 }
@@ -0,0 +1,193 @@
+package utils
+
+import (
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"testing"
+)
+
+func git(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %s\n%s", strings.Join(args, " "), err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// setupRemote creates a bare repository with a main branch, and a working copy pushing to it.
+func setupRemote(t *testing.T) (remote string, work string) {
+	t.Helper()
+	t.Setenv("GIT_AUTHOR_NAME", "sunbeam")
+	t.Setenv("GIT_AUTHOR_EMAIL", "sunbeam@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "sunbeam")
+	t.Setenv("GIT_COMMITTER_EMAIL", "sunbeam@example.com")
+
+	root := t.TempDir()
+	remote = path.Join(root, "remote.git")
+	work = path.Join(root, "work")
+
+	git(t, root, "init", "--quiet", "--bare", "--initial-branch=main", remote)
+	git(t, root, "clone", "--quiet", remote, work)
+	git(t, work, "checkout", "--quiet", "-b", "main")
+	commit(t, work, "initial commit")
+	git(t, work, "push", "--quiet", "origin", "main")
+
+	return remote, work
+}
+
+func commit(t *testing.T, work string, message string) string {
+	t.Helper()
+	if err := os.WriteFile(path.Join(work, "sunbeam.yml"), []byte(message), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git(t, work, "add", "sunbeam.yml")
+	git(t, work, "commit", "--quiet", "-m", message)
+	return git(t, work, "rev-parse", "HEAD")
+}
+
+func TestGetLatestVersion(t *testing.T) {
+	remote, work := setupRemote(t)
+
+	extensionDir := path.Join(t.TempDir(), "extension")
+	git(t, ".", "clone", "--quiet", remote, extensionDir)
+	gc := NewGitClient(extensionDir)
+
+	latest := commit(t, work, "second commit")
+	git(t, work, "push", "--quiet", "origin", "main")
+
+	branch, err := gc.GetDefaultBranch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if branch != "main" {
+		t.Errorf("default branch: got %q, want %q", branch, "main")
+	}
+
+	version, err := gc.GetLatestVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != latest {
+		t.Errorf("latest version: got %q, want %q", version, latest)
+	}
+
+	if err := gc.Checkout(branch); err != nil {
+		t.Fatal(err)
+	}
+	if current := gc.GetCurrentVersion(); current != latest {
+		t.Errorf("current version after checkout: got %q, want %q", current, latest)
+	}
+}
+
+func TestGetLatestTag(t *testing.T) {
+	remote, work := setupRemote(t)
+
+	for _, tag := range []string{"v1.2.0", "v1.10.0", "v2.0.0-beta", "nightly"} {
+		commit(t, work, tag)
+		git(t, work, "tag", "-a", "-m", tag, tag)
+	}
+	git(t, work, "push", "--quiet", "--tags", "origin", "main")
+	tagged := git(t, work, "rev-parse", "v1.10.0^{commit}")
+
+	extensionDir := path.Join(t.TempDir(), "extension")
+	git(t, ".", "clone", "--quiet", "--branch", "v1.2.0", remote, extensionDir)
+	gc := NewGitClient(extensionDir)
+
+	tag, err := gc.GetLatestTag()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tag != "v1.10.0" {
+		t.Errorf("latest tag: got %q, want %q", tag, "v1.10.0")
+	}
+
+	ref, err := gc.GetLatestRef()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref != "v1.10.0" {
+		t.Errorf("latest ref of a tagged checkout: got %q, want %q", ref, "v1.10.0")
+	}
+
+	sha, err := gc.ResolveRef(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sha != tagged {
+		t.Errorf("resolved annotated tag: got %q, want %q", sha, tagged)
+	}
+
+	if err := gc.Checkout(ref); err != nil {
+		t.Fatal(err)
+	}
+	if current := gc.GetCurrentTag(); current != "v1.10.0" {
+		t.Errorf("current tag after checkout: got %q, want %q", current, "v1.10.0")
+	}
+
+	if _, err := gc.ResolveRef("missing"); err == nil {
+		t.Error("expected an error when resolving a missing ref")
+	}
+}
+
+func TestGetLatestRefOfTaggedBranch(t *testing.T) {
+	remote, work := setupRemote(t)
+	git(t, work, "tag", "v1.0.0")
+	git(t, work, "push", "--quiet", "--tags", "origin", "main")
+
+	// The extension is on main, whose HEAD is also tagged
+	extensionDir := path.Join(t.TempDir(), "extension")
+	git(t, ".", "clone", "--quiet", "--branch", "main", remote, extensionDir)
+	gc := NewGitClient(extensionDir)
+
+	latest := commit(t, work, "second commit")
+	git(t, work, "push", "--quiet", "origin", "main")
+
+	ref, err := gc.GetLatestRef()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref != "main" {
+		t.Errorf("latest ref of a tagged branch: got %q, want %q", ref, "main")
+	}
+
+	version, err := gc.GetLatestVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != latest {
+		t.Errorf("latest version: got %q, want %q", version, latest)
+	}
+
+	// A newer tag does not make the extension leave its branch
+	commit(t, work, "third commit")
+	git(t, work, "tag", "v2.0.0")
+	git(t, work, "push", "--quiet", "--tags", "origin", "main")
+
+	if ref, _ := gc.GetLatestRef(); ref != "main" {
+		t.Errorf("latest ref after a new tag: got %q, want %q", ref, "main")
+	}
+}
+
+func TestParseGitSource(t *testing.T) {
+	cases := map[string]GitSource{
+		"https://github.com/pomdtr/extensions":                     {Url: "https://github.com/pomdtr/extensions"},
+		"https://github.com/pomdtr/extensions#tldr":                {Url: "https://github.com/pomdtr/extensions", Path: "tldr"},
+		"https://github.com/pomdtr/extensions#extensions/tldr/":    {Url: "https://github.com/pomdtr/extensions", Path: "extensions/tldr"},
+		"https://github.com/pomdtr/extensions#extensions/tldr@v1":  {Url: "https://github.com/pomdtr/extensions", Path: "extensions/tldr", Ref: "v1"},
+		"git@github.com:pomdtr/extensions.git#@main":               {Url: "git@github.com:pomdtr/extensions.git", Ref: "main"},
+		"git@github.com:pomdtr/extensions.git#devdocs@feature/foo": {Url: "git@github.com:pomdtr/extensions.git", Path: "devdocs", Ref: "feature/foo"},
+	}
+
+	for source, want := range cases {
+		t.Run(source, func(t *testing.T) {
+			if got := ParseGitSource(source); got != want {
+				t.Errorf("got %+v, want %+v", got, want)
+			}
+		})
+	}
+}